@@ -2,32 +2,51 @@ package main
 
 import (
 	"context"
+	"errors"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 )
 
 // ApplicationService provides a service to find and install GitHub release assets.
 type ApplicationService struct {
+	repo       Repository
 	asset      AssetRepository
 	execBinary ExecBinaryRepository
+	state      StateRepository
 }
 
-// newApplicationService returns a new [ApplicationService] object.
-func newApplicationService(asset AssetRepository, execBinary ExecBinaryRepository) *ApplicationService {
+// newApplicationService returns a new [ApplicationService] object for given GitHub repository.
+func newApplicationService(repo Repository, asset AssetRepository, execBinary ExecBinaryRepository, state StateRepository) *ApplicationService {
 	return &ApplicationService{
+		repo:       repo,
 		asset:      asset,
 		execBinary: execBinary,
+		state:      state,
 	}
 }
 
 // find finds a GitHub release asset in given release which matches given patterns and returns it and an executable binary in it.
-func (app *ApplicationService) find(ctx context.Context, tag string, patterns map[string]string) (Asset, ExecBinary, error) {
+// installName is a template of executable binary name to install as. If it is empty, executable binary is installed as same name as one in GitHub release asset.
+func (app *ApplicationService) find(ctx context.Context, tag string, patterns map[string]string, installName string) (Asset, ExecBinary, error) {
 	ps, err := parsePatterns(patterns)
 	if err != nil {
 		return Asset{}, ExecBinary{}, err
 	}
 
-	assets, err := app.asset.list(ctx, Release{
+	tmpl, err := parseInstallNameTemplate(installName)
+	if err != nil {
+		return Asset{}, ExecBinary{}, err
+	}
+
+	release := Release{
 		tag: tag,
-	})
+	}
+
+	assets, err := app.asset.list(ctx, release)
 	if err != nil {
 		return Asset{}, ExecBinary{}, err
 	}
@@ -37,7 +56,7 @@ func (app *ApplicationService) find(ctx context.Context, tag string, patterns ma
 		return Asset{}, ExecBinary{}, err
 	}
 
-	execBinary, err := pattern.execute(asset)
+	execBinary, err := pattern.execute(release, asset, tmpl)
 	if err != nil {
 		return Asset{}, ExecBinary{}, err
 	}
@@ -45,17 +64,424 @@ func (app *ApplicationService) find(ctx context.Context, tag string, patterns ma
 	return asset, execBinary, nil
 }
 
-// install downloads a GitHub release asset, extracts an executable binary from it, and writes it.
-func (app *ApplicationService) install(ctx context.Context, asset Asset, execBinary ExecBinary) error {
+// InstallOptions are options to install an executable binary.
+type InstallOptions struct {
+	// allowArchMismatch allows to install an executable binary built for platform other than [defaultPlatform].
+	allowArchMismatch bool
+
+	// verifyRun is a command to run freshly installed executable binary to verify it works.
+	// If verification fails, previous executable binary is restored. Verification is skipped if this is empty.
+	verifyRun string
+
+	// verifyVersion requires output of verifyRun command to contain release tag or semantic version.
+	verifyVersion bool
+
+	// verifyTimeout is a duration after which verifyRun command is killed.
+	verifyTimeout time.Duration
+
+	// versionProbe is a command to run executable binary which is already installed to check whether it is release to install.
+	// This is used only if [InstallRecord] can't tell version of executable binary which is already installed.
+	versionProbe string
+
+	// force installs executable binary even if same one is already installed.
+	force bool
+
+	// patterns are patterns which were used to find GitHub release asset.
+	// These are recorded into [InstallRecord] so that executable binary can be upgraded in same way.
+	patterns map[string]string
+
+	// installName is a template of executable binary name to install as which was used to find executable binary.
+	// This is recorded into [InstallRecord] so that executable binary can be upgraded in same way.
+	installName string
+
+	// shareDir is a share directory where companion files in archive such as man pages and shell completions are installed into.
+	// Companion files are not installed if this is empty.
+	shareDir string
+
+	// tree installs whole archive tree under prefix and links executable binary in it into directory instead of extracting only executable binary.
+	// Archive tree is not installed if this is nil.
+	tree *TreeOptions
+
+	// limits are limits on extracting release asset. Zero fields mean values in [defaultExtractLimits].
+	limits ExtractLimits
+
+	// workflowRun is an ID of GitHub Actions workflow run whose artifact executable binary is installed from.
+	// This is recorded into [InstallRecord] so that executable binary is not upgraded to release. This is zero if executable binary is installed from release.
+	workflowRun int64
+}
+
+// InstallResult is a result of installing an executable binary.
+type InstallResult struct {
+	// format is a result of inspecting header of installed executable binary.
+	format ExecBinaryFormat
+
+	// archMismatch is an error describing that installed executable binary was built for platform other than [defaultPlatform].
+	// This is nil if executable binary runs on [defaultPlatform].
+	archMismatch error
+
+	// verified is true if installed executable binary was verified by running it.
+	verified bool
+
+	// record is a record of installed executable binary saved into [StateRepository].
+	record InstallRecord
+
+	// upToDate is true if same executable binary was already installed and nothing was written.
+	upToDate bool
+
+	// size is a size of executable binary in bytes.
+	size int
+
+	// digest is a digest of executable binary content in "sha256:HEX" format.
+	digest string
+}
+
+// installed returns [InstallRecord] of executable binary installed at path where given executable binary will be installed.
+// If no executable binary was installed there by this tool, this returns false.
+func (app *ApplicationService) installed(execBinary ExecBinary) (InstallRecord, bool, error) {
+	path, err := filepath.Abs(app.execBinary.path(execBinary))
+	if err != nil {
+		return InstallRecord{}, false, err
+	}
+	records, err := app.state.list()
+	if err != nil {
+		return InstallRecord{}, false, err
+	}
+	for _, r := range records {
+		if r.Path == path {
+			return r, true, nil
+		}
+	}
+	return InstallRecord{}, false, nil
+}
+
+// upToDate returns true if executable binary from given release is already installed.
+// Version of executable binary which is already installed is determined by [InstallRecord] of it or by running it with version probe command.
+// If neither of them can tell version, this returns false and [ApplicationService.install] compares digests after download.
+func (app *ApplicationService) upToDate(ctx context.Context, tag string, execBinary ExecBinary, opts InstallOptions) (bool, error) {
+	content, err := app.execBinary.read(execBinary)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	record, ok, err := app.installed(execBinary)
+	if err != nil {
+		return false, err
+	}
+	if ok && record.Digest == digest(content) {
+		return record.Repo == app.repo.String() && record.Tag == tag && app.sameTree(record, Release{tag: tag}, opts), nil
+	}
+
+	if opts.versionProbe == "" {
+		return false, nil
+	}
+	out, err := runExecBinary(ctx, app.execBinary.path(execBinary), opts.versionProbe, opts.verifyTimeout)
+	if err != nil {
+		return false, nil // Version of executable binary is unknown if it can't be run.
+	}
+	return containsVersion(out, Release{tag: tag}), nil
+}
+
+// install downloads a GitHub release asset in given release, extracts an executable binary from it, and writes it.
+// Executable binary is inspected before it is written and is not written if it doesn't run on [defaultPlatform] unless it is explicitly allowed.
+// If verification command is given, executable binary is run after it is written and previous one is restored if verification fails.
+// Record of installed executable binary is saved into [StateRepository].
+// If same executable binary is already installed, nothing is written unless it is forced.
+func (app *ApplicationService) install(ctx context.Context, tag string, asset Asset, execBinary ExecBinary, opts InstallOptions) (InstallResult, error) {
 	assetContent, err := app.asset.download(ctx, asset)
 	if err != nil {
-		return err
+		return InstallResult{}, err
+	}
+	if opts.tree != nil {
+		return app.installTree(ctx, tag, asset, assetContent, execBinary, opts)
 	}
 
-	execBinaryContent, err := assetContent.extract(execBinary)
+	execBinaryContent, err := assetContent.extract(ctx, execBinary, opts.limits)
 	if err != nil {
-		return err
+		return InstallResult{}, err
 	}
 
-	return app.execBinary.write(execBinary, execBinaryContent)
+	format, err := execBinaryContent.inspect()
+	if err != nil {
+		return InstallResult{}, err
+	}
+	archMismatch := format.check(defaultPlatform)
+	if archMismatch != nil && !opts.allowArchMismatch {
+		return InstallResult{}, archMismatch
+	}
+
+	if !opts.force {
+		installed, err := app.execBinary.read(execBinary)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return InstallResult{}, err
+		}
+		if err == nil && digest(installed) == digest(execBinaryContent) {
+			// Same executable binary may be shipped by other release or have been installed without record, so record is saved anyway.
+			previous, _, err := app.installed(execBinary)
+			if err != nil {
+				return InstallResult{}, err
+			}
+			record, err := app.newInstallRecord(Release{tag: tag}, asset, execBinary, opts, digest(execBinaryContent), previous)
+			if err != nil {
+				return InstallResult{}, err
+			}
+			if err := app.state.save(record); err != nil {
+				return InstallResult{}, err
+			}
+			return InstallResult{
+				format:       format,
+				archMismatch: archMismatch,
+				upToDate:     true,
+				size:         len(execBinaryContent),
+				digest:       digest(execBinaryContent),
+				record:       record,
+			}, nil
+		}
+	}
+
+	restore, err := app.execBinary.backup(execBinary)
+	if err != nil {
+		return InstallResult{}, err
+	}
+
+	release := Release{
+		tag: tag,
+	}
+	if err := app.execBinary.write(release, execBinary, execBinaryContent); err != nil {
+		return InstallResult{}, err
+	}
+
+	result := InstallResult{
+		format:       format,
+		archMismatch: archMismatch,
+		size:         len(execBinaryContent),
+		digest:       digest(execBinaryContent),
+	}
+	if opts.verifyRun != "" {
+		if err := verifyExecBinary(ctx, app.execBinary.path(execBinary), opts.verifyRun, release, opts.verifyVersion, opts.verifyTimeout); err != nil {
+			return InstallResult{}, errors.Join(err, restore())
+		}
+		result.verified = true
+	}
+
+	previous, _, err := app.installed(execBinary)
+	if err != nil {
+		return InstallResult{}, errors.Join(err, restore())
+	}
+	companions, shareDir, err := updateCompanions(ctx, assetContent, execBinary, opts, previous)
+	if err != nil {
+		return InstallResult{}, errors.Join(err, restore())
+	}
+	result.record, err = app.newInstallRecord(release, asset, execBinary, opts, result.digest, previous)
+	if err != nil {
+		return InstallResult{}, errors.Join(err, restore())
+	}
+	result.record.Companions = companions
+	result.record.ShareDir = shareDir
+	if storeOf(result.record.Path, app.repo) != "" {
+		if result.record.AssetURLs == nil {
+			result.record.AssetURLs = map[string]string{}
+		}
+		result.record.AssetURLs[release.tag] = result.record.AssetURL
+	}
+	if err := app.state.save(result.record); err != nil {
+		return InstallResult{}, errors.Join(err, restore())
+	}
+
+	return result, nil
+}
+
+// newInstallRecord returns [InstallRecord] of executable binary which has given digest and was extracted from given asset in given release.
+// Companion files and asset URLs of versions kept in store are carried over from previous record.
+func (app *ApplicationService) newInstallRecord(release Release, asset Asset, execBinary ExecBinary, opts InstallOptions, contentDigest string, previous InstallRecord) (InstallRecord, error) {
+	path, err := filepath.Abs(app.execBinary.path(execBinary))
+	if err != nil {
+		return InstallRecord{}, err
+	}
+	record := InstallRecord{
+		Repo:         app.repo.String(),
+		Tag:          release.tag,
+		AssetURL:     asset.downloadURL.String(),
+		WorkflowRun:  opts.workflowRun,
+		Name:         execBinary.name,
+		Patterns:     opts.patterns,
+		NameTemplate: opts.installName,
+		Path:         path,
+		Digest:       contentDigest,
+		InstalledAt:  time.Now().UTC(),
+		Companions:   previous.Companions,
+		ShareDir:     previous.ShareDir,
+	}
+	if previous.Repo == record.Repo {
+		record.AssetURLs = maps.Clone(previous.AssetURLs)
+	}
+	return record, nil
+}
+
+// updateCompanions installs companion files in given asset content if share directory is given, and removes ones of previous installation which are no longer shipped.
+// If share directory is not given, companion files of previous installation are kept as they are.
+// This returns records of companion files and share directory to save into [InstallRecord].
+func updateCompanions(ctx context.Context, assetContent AssetContent, execBinary ExecBinary, opts InstallOptions, previous InstallRecord) ([]CompanionRecord, string, error) {
+	if opts.shareDir == "" {
+		return previous.Companions, previous.ShareDir, nil
+	}
+	files, err := assetContent.companions(ctx, execBinary, opts.limits)
+	if err != nil {
+		return nil, "", err
+	}
+	companions, err := installCompanions(opts.shareDir, files)
+	if err != nil {
+		return nil, "", err
+	}
+	stale := slices.DeleteFunc(previous.Companions, func(c CompanionRecord) bool {
+		return slices.ContainsFunc(companions, func(n CompanionRecord) bool { return n.Path == c.Path })
+	})
+	if _, err := removeCompanions(stale); err != nil {
+		return nil, "", err
+	}
+	return companions, opts.shareDir, nil
+}
+
+// InstallPlan is a plan of installing an executable binary, which tells what would be written where.
+type InstallPlan struct {
+	// path is an absolute path where executable binary would be written.
+	path string
+
+	// replaces is an executable binary which already exists at path and would be replaced. This is nil if nothing exists at path.
+	replaces *ReplacedExecBinary
+
+	// upToDate is true if same executable binary is already installed and nothing would be written.
+	upToDate bool
+
+	// extracted is true if release asset was downloaded and executable binary was extracted from it.
+	// Following fields are set only if this is true.
+	extracted bool
+
+	// format is a result of inspecting header of extracted executable binary.
+	format ExecBinaryFormat
+
+	// archMismatch is an error describing that extracted executable binary was built for platform other than [defaultPlatform].
+	archMismatch error
+
+	// size is a size of extracted executable binary in bytes.
+	size int
+
+	// digest is a digest of extracted executable binary content in "sha256:HEX" format.
+	digest string
+
+	// treeRoot is an absolute path of directory which archive would be extracted into. This is empty unless archive tree is installed.
+	treeRoot string
+
+	// companions are absolute paths where companion files in archive would be written. This is set only if extracted is true and share directory is given.
+	companions []string
+}
+
+// ReplacedExecBinary is an executable binary which already exists and would be replaced.
+type ReplacedExecBinary struct {
+	// record is [InstallRecord] of executable binary. This is nil if it was not installed by this tool or was modified since then.
+	record *InstallRecord
+
+	// version is output of version probe command. This is empty if version probe command was not given or failed.
+	version string
+
+	// digest is a digest of executable binary content in "sha256:HEX" format.
+	digest string
+}
+
+// plan resolves what [ApplicationService.install] would do without writing anything.
+// If extract is true, GitHub release asset is downloaded and executable binary is extracted and inspected to confirm it can be installed.
+func (app *ApplicationService) plan(ctx context.Context, tag string, asset Asset, execBinary ExecBinary, opts InstallOptions, extract bool) (InstallPlan, error) {
+	path, err := filepath.Abs(app.execBinary.path(execBinary))
+	if err != nil {
+		return InstallPlan{}, err
+	}
+	plan := InstallPlan{
+		path: path,
+	}
+	release := Release{
+		tag: tag,
+	}
+	if opts.tree != nil {
+		plan.treeRoot, err = filepath.Abs(opts.tree.treeRoot(app.repo, release))
+		if err != nil {
+			return InstallPlan{}, err
+		}
+	}
+
+	current, err := app.execBinary.read(execBinary)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return InstallPlan{}, err
+	}
+	if err == nil {
+		plan.replaces = &ReplacedExecBinary{
+			digest: digest(current),
+		}
+		record, ok, err := app.installed(execBinary)
+		if err != nil {
+			return InstallPlan{}, err
+		}
+		if ok && record.Digest == plan.replaces.digest {
+			plan.replaces.record = &record
+			plan.upToDate = record.Repo == app.repo.String() && record.Tag == tag && app.sameTree(record, release, opts)
+		} else if opts.versionProbe != "" {
+			if out, err := runExecBinary(ctx, path, opts.versionProbe, opts.verifyTimeout); err == nil {
+				plan.replaces.version = strings.TrimSpace(strings.SplitN(out, "\n", 2)[0])
+				plan.upToDate = containsVersion(out, release)
+			}
+		}
+	}
+	if opts.force {
+		plan.upToDate = false
+	}
+
+	if !extract {
+		return plan, nil
+	}
+
+	assetContent, err := app.asset.download(ctx, asset)
+	if err != nil {
+		return InstallPlan{}, err
+	}
+	var execBinaryContent ExecBinaryContent
+	if opts.tree != nil {
+		execBinaryContent, err = planTree(ctx, assetContent, execBinary, *opts.tree, opts.limits)
+	} else {
+		execBinaryContent, err = assetContent.extract(ctx, execBinary, opts.limits)
+	}
+	if err != nil {
+		return InstallPlan{}, err
+	}
+	if opts.shareDir != "" {
+		files, err := assetContent.companions(ctx, execBinary, opts.limits)
+		if err != nil {
+			return InstallPlan{}, err
+		}
+		for _, f := range files {
+			p, err := companionPath(opts.shareDir, f)
+			if err != nil {
+				return InstallPlan{}, err
+			}
+			plan.companions = append(plan.companions, p)
+		}
+	}
+	format, err := execBinaryContent.inspect()
+	if err != nil {
+		return InstallPlan{}, err
+	}
+	archMismatch := format.check(defaultPlatform)
+	if archMismatch != nil && !opts.allowArchMismatch {
+		return InstallPlan{}, archMismatch
+	}
+
+	plan.extracted = true
+	plan.format = format
+	plan.archMismatch = archMismatch
+	plan.size = len(execBinaryContent)
+	plan.digest = digest(execBinaryContent)
+	if !opts.force && plan.replaces != nil && plan.replaces.digest == plan.digest && (opts.tree == nil || plan.replaces.record != nil && app.sameTree(*plan.replaces.record, release, opts)) {
+		plan.upToDate = true
+	}
+	return plan, nil
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAssetRepository is an in-memory [AssetRepository] for tests.
+type fakeAssetRepository struct {
+	contents map[string]AssetContent // keyed by download URL.
+}
+
+func (r *fakeAssetRepository) list(_ context.Context, _ Release) ([]Asset, error) {
+	assets := []Asset{}
+	for u := range r.contents {
+		assets = append(assets, Asset{downloadURL: must(url.Parse(u))})
+	}
+	return assets, nil
+}
+
+func (r *fakeAssetRepository) download(_ context.Context, asset Asset) (AssetContent, error) {
+	return r.contents[asset.downloadURL.String()], nil
+}
+
+// newFakeApplicationService returns [ApplicationService] object which installs test binary itself as "tool" from fake GitHub release asset.
+func newFakeApplicationService(t *testing.T, dir string) *ApplicationService {
+	t.Helper()
+	b, err := os.ReadFile(os.Args[0])
+	require.NoError(t, err)
+	asset := &fakeAssetRepository{
+		contents: map[string]AssetContent{
+			"https://github.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64": b,
+		},
+	}
+	repo := Repository{host: "github.com", owner: "owner", name: "tool"}
+	state := newStateRepository(filepath.Join(dir, ".state.json"))
+	return newApplicationService(repo, asset, newFSExecBinaryRepository(dir), state)
+}
+
+func TestApplicationServiceUpToDate(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	app := newFakeApplicationService(t, dir)
+	ctx := context.Background()
+
+	asset, execBinary, err := app.find(ctx, "v1.0.0", defaultPatterns, "")
+	require.NoError(err)
+	require.Equal(ExecBinary{name: "tool", installName: "tool"}, execBinary)
+
+	upToDate, err := app.upToDate(ctx, "v1.0.0", execBinary, InstallOptions{})
+	require.NoError(err)
+	require.False(upToDate)
+
+	result, err := app.install(ctx, "v1.0.0", asset, execBinary, InstallOptions{})
+	require.NoError(err)
+	require.False(result.upToDate)
+
+	upToDate, err = app.upToDate(ctx, "v1.0.0", execBinary, InstallOptions{})
+	require.NoError(err)
+	require.True(upToDate, "install record should tell executable binary is up to date")
+
+	upToDate, err = app.upToDate(ctx, "v1.1.0", execBinary, InstallOptions{})
+	require.NoError(err)
+	require.False(upToDate)
+
+	require.NoError(os.Remove(filepath.Join(dir, ".state.json")))
+	result, err = app.install(ctx, "v1.0.0", asset, execBinary, InstallOptions{})
+	require.NoError(err)
+	require.True(result.upToDate, "digest should tell executable binary is up to date")
+	upToDate, err = app.upToDate(ctx, "v1.0.0", execBinary, InstallOptions{})
+	require.NoError(err)
+	require.True(upToDate, "install record should be saved even if executable binary is up to date")
+
+	result, err = app.install(ctx, "v1.0.0", asset, execBinary, InstallOptions{force: true})
+	require.NoError(err)
+	require.False(result.upToDate)
+}
+
+func TestApplicationServicePlan(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	app := newFakeApplicationService(t, dir)
+	ctx := context.Background()
+
+	asset, execBinary, err := app.find(ctx, "v1.0.0", defaultPatterns, "")
+	require.NoError(err)
+
+	plan, err := app.plan(ctx, "v1.0.0", asset, execBinary, InstallOptions{}, true)
+	require.NoError(err)
+	require.Equal(filepath.Join(dir, "tool"), plan.path)
+	require.Nil(plan.replaces)
+	require.False(plan.upToDate)
+	require.True(plan.extracted)
+	require.NoFileExists(filepath.Join(dir, "tool"), "dry run should write nothing")
+
+	_, err = app.install(ctx, "v1.0.0", asset, execBinary, InstallOptions{})
+	require.NoError(err)
+
+	plan, err = app.plan(ctx, "v1.1.0", asset, execBinary, InstallOptions{}, false)
+	require.NoError(err)
+	require.NotNil(plan.replaces)
+	require.Equal("v1.0.0", plan.replaces.record.Tag)
+	require.False(plan.upToDate)
+	require.False(plan.extracted)
+
+	plan, err = app.plan(ctx, "v1.0.0", asset, execBinary, InstallOptions{}, false)
+	require.NoError(err)
+	require.True(plan.upToDate)
+}
+
+// failingStateRepository is a [StateRepository] which fails to save records.
+type failingStateRepository struct {
+	StateRepository
+}
+
+func (r failingStateRepository) save(_ InstallRecord) error {
+	return errors.New("disk full")
+}
+
+func TestApplicationServiceInstallRestoresOnSaveFailure(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	app := newFakeApplicationService(t, dir)
+	app.state = failingStateRepository{StateRepository: app.state}
+	ctx := context.Background()
+
+	asset, execBinary, err := app.find(ctx, "v1.0.0", defaultPatterns, "")
+	require.NoError(err)
+	_, err = app.install(ctx, "v1.0.0", asset, execBinary, InstallOptions{})
+	require.Error(err)
+	require.NoFileExists(filepath.Join(dir, "tool"), "executable binary without record should be removed")
+}
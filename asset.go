@@ -4,13 +4,18 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"cmp"
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/url"
-	"path/filepath"
+	"path"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/ulikunitz/xz"
@@ -20,26 +25,118 @@ import (
 type Asset struct {
 	id          int64
 	downloadURL *url.URL
+	size        int64 // size of asset in bytes, which is used for progress bar. Zero means unknown.
 }
 
 // AssetContent represents a GitHub release asset content.
 type AssetContent []byte
 
-// extracts [ExecBinaryContent] from [AssetContent] and returns it.
-func (a AssetContent) extract(execBinary ExecBinary) (ExecBinaryContent, error) {
+// ExtractLimits are limits on extracting release asset, which protect against malicious or malformed archives such as zip bombs.
+// Zero fields mean values in [defaultExtractLimits].
+type ExtractLimits struct {
+	// maxDepth is a maximum number of nested archives and compressions, such as 2 for ".tar.gz".
+	maxDepth int
+
+	// maxSize is a maximum size in bytes of content decompressed or unarchived from each layer.
+	maxSize int64
+
+	// maxRatio is a maximum ratio of decompressed size to compressed size of each layer.
+	maxRatio float64
+
+	// timeout is a duration after which extracting is aborted.
+	timeout time.Duration
+}
+
+// defaultExtractLimits are limits used unless they are given explicitly.
+var defaultExtractLimits = ExtractLimits{
+	maxDepth: 8,
+	maxSize:  2 << 30,
+	maxRatio: 1000,
+	timeout:  5 * time.Minute,
+}
+
+// ratioCheckThreshold is a size of decompressed content under which compression ratio is not checked,
+// because small files which are mostly zeros are compressed with high ratio legitimately.
+const ratioCheckThreshold = 1 << 20
+
+// orDefault returns limits whose zero fields are replaced by ones of [defaultExtractLimits].
+func (l ExtractLimits) orDefault() ExtractLimits {
+	return ExtractLimits{
+		maxDepth: cmp.Or(l.maxDepth, defaultExtractLimits.maxDepth),
+		maxSize:  cmp.Or(l.maxSize, defaultExtractLimits.maxSize),
+		maxRatio: cmp.Or(l.maxRatio, defaultExtractLimits.maxRatio),
+		timeout:  cmp.Or(l.timeout, defaultExtractLimits.timeout),
+	}
+}
+
+// withTimeout returns a context which is canceled after timeout of limits.
+func (l ExtractLimits) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeoutCause(ctx, l.timeout, withCode(ErrExtractTimeout, fmt.Errorf("extracting asset took longer than %s", l.timeout)))
+}
+
+// checkDepth returns an error if given number of nested layers exceeds limit.
+func (l ExtractLimits) checkDepth(depth int) error {
+	if depth > l.maxDepth {
+		return withCode(ErrNestingTooDeep, fmt.Errorf("archives or compressions in asset were nested deeper than %d", l.maxDepth))
+	}
+	return nil
+}
+
+// reader returns a [io.Reader] which reads r and fails once content read from it exceeds limits or context is done.
+// compressed is a size of input which r decompresses, which is used to check compression ratio.
+func (l ExtractLimits) reader(ctx context.Context, r io.Reader, compressed int64) io.Reader {
+	return &limitedReader{ctx: ctx, r: r, limits: l, compressed: compressed}
+}
+
+// limitedReader is a [io.Reader] returned by [ExtractLimits.reader].
+type limitedReader struct {
+	ctx        context.Context
+	r          io.Reader
+	limits     ExtractLimits
+	compressed int64
+	n          int64
+}
+
+// Read implements [io.Reader].
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.ctx.Err() != nil {
+		return 0, context.Cause(r.ctx)
+	}
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	if r.n > r.limits.maxSize {
+		return n, withCode(ErrExtractTooLarge, fmt.Errorf("content extracted from asset exceeded %d bytes", r.limits.maxSize))
+	}
+	if r.n > ratioCheckThreshold && float64(r.n) > float64(max(r.compressed, 1))*r.limits.maxRatio {
+		return n, withCode(ErrCompressionRatio, fmt.Errorf("content extracted from asset exceeded compression ratio %g", r.limits.maxRatio))
+	}
+	return n, err
+}
+
+// extract extracts [ExecBinaryContent] from [AssetContent] and returns it.
+// Each layer of archives and compressions is extracted within given limits.
+func (a AssetContent) extract(ctx context.Context, execBinary ExecBinary, limits ExtractLimits) (ExecBinaryContent, error) {
+	limits = limits.orDefault()
+	ctx, cancel := limits.withTimeout(ctx)
+	defer cancel()
+
 	b := []byte(a)
-	for !isExecBinaryContent(b) {
-		r, err := newReaderToExtract(b, execBinary)
-		if err != nil {
+	for depth := 1; !isExecBinaryContent(b); depth++ {
+		if err := limits.checkDepth(depth); err != nil {
 			return nil, err
 		}
-		b, err = io.ReadAll(r)
+		r, err := newReaderToExtract(b, execBinary)
+		if errors.Is(err, io.EOF) {
+			return nil, withCode(ErrExecBinaryNotFound, fmt.Errorf("%s was not found in asset", execBinary.name))
+		}
 		if err != nil {
 			return nil, err
 		}
-		if err := r.Close(); err != nil {
+		out, err := io.ReadAll(limits.reader(ctx, r, int64(len(b))))
+		if err := errors.Join(err, r.Close()); err != nil {
 			return nil, err
 		}
+		b = out
 	}
 	return ExecBinaryContent(b), nil
 }
@@ -71,37 +168,179 @@ func newReaderToExtract(b []byte, execBinary ExecBinary) (io.ReadCloser, error)
 	case "application/zip":
 		return newZipReader(br, br.Size(), execBinary.name)
 	default:
-		return nil, fmt.Errorf("MIME type of asset content was unexpected: %s", mime.String())
+		return nil, withCode(ErrUnsupportedArchive, fmt.Errorf("MIME type of asset content was unexpected: %s", mime.String()))
 	}
 }
 
 // newTarReader returns a [io.ReadCloser] to read file which is given name in tarball.
+// If no regular file has given name, symbolic link or hard link which has it is resolved to regular file it points within tarball.
 // Closing [io.ReadCloser] is caller's responsibility.
-func newTarReader(r io.Reader, name string) (io.ReadCloser, error) {
-	for tr := tar.NewReader(r); ; {
+func newTarReader(r io.ReadSeeker, name string) (io.ReadCloser, error) {
+	headers := map[string]*tar.Header{}
+	indices := map[string]int{}
+	regular, link := "", ""
+	tr := tar.NewReader(r)
+	for i := 0; ; i++ {
 		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
 		if err != nil {
 			return nil, err
 		}
-		if filepath.Base(header.Name) == name && header.Typeflag == tar.TypeReg {
-			return io.NopCloser(tr), nil
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeSymlink && header.Typeflag != tar.TypeLink {
+			continue
+		}
+		p := path.Clean(header.Name)
+		if _, ok := headers[p]; !ok {
+			headers[p], indices[p] = header, i
+		}
+		switch {
+		case path.Base(p) != name:
+		case header.Typeflag == tar.TypeReg && regular == "":
+			regular = p
+		case header.Typeflag != tar.TypeReg && link == "":
+			link = p
+		}
+	}
+	if regular != "" {
+		return readTarEntry(r, indices[regular])
+	}
+	if link == "" {
+		return nil, io.EOF
+	}
+
+	target, err := resolveArchiveLinks(link, func(p string) (string, bool, bool, error) {
+		header, ok := headers[p]
+		if !ok || header.Typeflag == tar.TypeReg {
+			return "", false, false, nil
+		}
+		return header.Linkname, header.Typeflag == tar.TypeLink, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if header, ok := headers[target]; !ok || header.Typeflag != tar.TypeReg {
+		return nil, withCode(ErrExecBinaryNotFound, fmt.Errorf("%s in archive pointed %s which was not regular file", link, target))
+	}
+	return readTarEntry(r, indices[target])
+}
+
+// readTarEntry returns a [io.ReadCloser] to read content of entry at given index in tarball.
+func readTarEntry(r io.ReadSeeker, index int) (io.ReadCloser, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(r)
+	for range index + 1 {
+		if _, err := tr.Next(); err != nil {
+			return nil, err
 		}
 	}
+	return io.NopCloser(tr), nil
 }
 
 // newZipReader returns a [io.ReadCloser] to read file which is given name in zip file.
+// If no regular file has given name, symbolic link which has it is resolved to regular file it points within zip file.
+// Symbolic links are entries whose Unix mode bits say so, and their contents are link targets.
 // Closing [io.ReadCloser] is caller's responsibility.
 func newZipReader(r io.ReaderAt, size int64, name string) (io.ReadCloser, error) {
 	zr, err := zip.NewReader(r, size)
 	if err != nil {
 		return nil, err
 	}
+	files := map[string]*zip.File{}
+	var regular, link *zip.File
 	for _, f := range zr.File {
-		if filepath.Base(f.Name) == name && !f.FileInfo().IsDir() {
-			return f.Open()
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		p := path.Clean(f.Name)
+		if _, ok := files[p]; !ok {
+			files[p] = f
+		}
+		symlink := f.Mode()&fs.ModeSymlink != 0
+		switch {
+		case path.Base(p) != name:
+		case !symlink && regular == nil:
+			regular = f
+		case symlink && link == nil:
+			link = f
+		}
+	}
+	if regular != nil {
+		return regular.Open()
+	}
+	if link == nil {
+		return nil, io.EOF
+	}
+
+	target, err := resolveArchiveLinks(path.Clean(link.Name), func(p string) (string, bool, bool, error) {
+		f, ok := files[p]
+		if !ok || f.Mode()&fs.ModeSymlink == 0 {
+			return "", false, false, nil
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", false, false, err
+		}
+		target, err := io.ReadAll(io.LimitReader(rc, 4096))
+		if err := errors.Join(err, rc.Close()); err != nil {
+			return "", false, false, err
+		}
+		return string(target), false, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	f, ok := files[target]
+	if !ok || f.Mode()&fs.ModeSymlink != 0 {
+		return nil, withCode(ErrExecBinaryNotFound, fmt.Errorf("%s in archive pointed %s which was not regular file", link.Name, target))
+	}
+	return f.Open()
+}
+
+// maxLinkHops is a maximum number of links followed to resolve path in archive, which stops link loops.
+const maxLinkHops = 40
+
+// resolveArchiveLinks resolves symbolic links and hard links in given path of entry in archive, including ones of parent directories,
+// and returns cleaned path of entry which it finally points. Links which point outside archive are rejected.
+// readlink returns target of link at given path, whether it is hard link whose target is relative to archive root, and whether entry at path is link.
+func resolveArchiveLinks(name string, readlink func(p string) (string, bool, bool, error)) (string, error) {
+	p := path.Clean(name)
+	for hops := 0; ; {
+		components := strings.Split(p, "/")
+		resolved := true
+		for i := range components {
+			prefix := strings.Join(components[:i+1], "/")
+			target, hard, ok, err := readlink(prefix)
+			if err != nil {
+				return "", err
+			}
+			if !ok {
+				continue
+			}
+			if hops++; hops > maxLinkHops {
+				return "", withCode(ErrUnsafeArchive, fmt.Errorf("links in archive were nested deeper than %d at %s", maxLinkHops, name))
+			}
+			if path.IsAbs(target) {
+				return "", withCode(ErrUnsafeArchive, fmt.Errorf("link %s in archive pointed absolute path: %s", prefix, target))
+			}
+			next := target
+			if !hard {
+				next = path.Join(path.Dir(prefix), target)
+			}
+			next = path.Join(next, strings.Join(components[i+1:], "/"))
+			if next == ".." || strings.HasPrefix(next, "../") {
+				return "", withCode(ErrUnsafeArchive, fmt.Errorf("link %s in archive pointed outside archive: %s", prefix, target))
+			}
+			p, resolved = next, false
+			break
+		}
+		if resolved {
+			return p, nil
 		}
 	}
-	return nil, io.EOF
 }
 
 // AssetRepository is an interface about repository for [Asset] and [AssetContent].
@@ -110,14 +349,46 @@ type AssetRepository interface {
 	download(ctx context.Context, asset Asset) (AssetContent, error)
 }
 
-// newAssetRepository returns a new [GitHubAssetRepository] object or [ExternalAssetRepository] object based on given repository name.
-func newAssetRepository(repo string, progressBar io.Writer) (AssetRepository, error) {
+// newAssetRepository returns a new [AssetRepository] object based on given repository name.
+// Release assets are downloaded from mirrors if mirror rules are configured.
+func newAssetRepository(repo string, config Config, progressBar io.Writer) (AssetRepository, error) {
+	r, err := newSourceAssetRepository(repo, config, progressBar)
+	if err != nil {
+		return nil, err
+	}
+	return newMirrorAssetRepository(r, config, progressBar), nil
+}
+
+// newSourceAssetRepository returns a new [AssetRepository] object which lists and downloads release assets from their source.
+// [OCIAssetRepository] is returned for repository in OCI registry.
+// [ExternalAssetRepository] is returned for repository whose release source is configured or known repository whose release assets are hosted on server other than GitHub.
+// Otherwise, [AssetRepository] object is chosen by type of repository's host in configuration.
+func newSourceAssetRepository(repo string, config Config, progressBar io.Writer) (AssetRepository, error) {
 	r, err := parseRepository(repo)
 	if err != nil {
 		return nil, err
 	}
+	if r.scheme == "oci" {
+		return newOCIAssetRepository(r, config.host(r.host), config.tool(r.String()).MediaType, progressBar), nil
+	}
+	if source := config.tool(r.String()).Source; source != nil {
+		return newExternalAssetRepositoryFromSource(*source, config.httpClient(), progressBar)
+	}
 	if templates, ok := externalAssetTemplates[r]; ok {
-		return newExternalAssetRepository(templates, progressBar), nil
+		return newExternalAssetRepository(templates, config.httpClient(), progressBar), nil
+	}
+	host := config.host(r.host)
+	if err := checkNamespace(r, host); err != nil {
+		return nil, err
+	}
+	switch host.Type {
+	case "github":
+		return newGitHubAssetRepository(r, host, progressBar)
+	case "gitlab":
+		return newGitLabAssetRepository(r, host, progressBar), nil
+	case "gitea":
+		return newGiteaAssetRepository(r, host, progressBar), nil
+	default:
+		return nil, fmt.Errorf("type of host %s was unknown: %s", r.host, host.Type)
 	}
-	return newGitHubAssetRepository(r, progressBar), nil
 }
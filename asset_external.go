@@ -8,8 +8,6 @@ import (
 	"net/url"
 	"slices"
 	"text/template"
-
-	"github.com/cheggaaa/pb/v3"
 )
 
 // externalAssetTemplates are templates of known release asset hosted on server other than GitHub.
@@ -83,19 +81,22 @@ func (a ExternalAssetTemplate) execute(release Release) (Asset, error) {
 
 // ExternalAssetRepository is a repository for [Asset] and [AssetContent] hosted on server other than GitHub.
 type ExternalAssetRepository struct {
+	client      *http.Client
 	templates   []ExternalAssetTemplate
-	progressBar io.Writer // written progress bar into when downloading a GitHub release asset.
+	index       *ExternalReleaseIndex // used to resolve releases. This is nil if releases are resolved by repository's host.
+	progressBar io.Writer             // written progress bar into when downloading a release asset from external URL.
 }
 
 // newExternalAssetRepository returns a new [ExternalAssetRepository] object.
-func newExternalAssetRepository(templates []ExternalAssetTemplate, progressBar io.Writer) *ExternalAssetRepository {
+func newExternalAssetRepository(templates []ExternalAssetTemplate, client *http.Client, progressBar io.Writer) *ExternalAssetRepository {
 	return &ExternalAssetRepository{
+		client:      client,
 		templates:   slices.Clone(templates),
 		progressBar: progressBar,
 	}
 }
 
-// list lists GitHub release assets in a given GitHub release and returns them.
+// list applies external URL templates to a given release and returns assets built from them.
 func (r *ExternalAssetRepository) list(_ context.Context, release Release) ([]Asset, error) {
 	assets := []Asset{}
 	for _, tmpl := range r.templates {
@@ -108,16 +109,11 @@ func (r *ExternalAssetRepository) list(_ context.Context, release Release) ([]As
 	return assets, nil
 }
 
-// download downloads a GitHub release asset content and returns it.
-func (r *ExternalAssetRepository) download(_ context.Context, asset Asset) (AssetContent, error) {
-	resp, err := http.Get(asset.downloadURL.String())
+// download downloads a release asset content from external URL built from template and returns it.
+func (r *ExternalAssetRepository) download(ctx context.Context, asset Asset) (AssetContent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.downloadURL.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close() // nolint:errcheck
-
-	pr := pb.Full.Start64(resp.ContentLength).SetWriter(r.progressBar).NewProxyReader(resp.Body)
-	defer pr.Close() // nolint:errcheck
-
-	return io.ReadAll(pr)
+	return download(r.client, req, asset.size, r.progressBar)
 }
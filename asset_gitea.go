@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+// GiteaAssetRepository is a repository for [Asset] and [AssetContent] attached to Gitea or Forgejo releases.
+type GiteaAssetRepository struct {
+	client      *giteaClient
+	progressBar io.Writer // written progress bar into when downloading a release asset.
+}
+
+// newGiteaAssetRepository returns a new [GiteaAssetRepository] object.
+func newGiteaAssetRepository(repo Repository, host HostConfig, progressBar io.Writer) *GiteaAssetRepository {
+	return &GiteaAssetRepository{
+		client:      newGiteaClient(repo, host),
+		progressBar: progressBar,
+	}
+}
+
+// list lists release assets in a given Gitea release and returns them.
+func (r *GiteaAssetRepository) list(ctx context.Context, release Release) ([]Asset, error) {
+	gr, err := r.client.release(ctx, release.tag)
+	if err != nil {
+		return nil, err
+	}
+	assets := []Asset{}
+	for _, a := range gr.Assets {
+		downloadURL, err := url.Parse(a.BrowserDownloadURL)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, Asset{
+			id:          a.ID,
+			downloadURL: downloadURL,
+			size:        a.Size,
+		})
+	}
+	return assets, nil
+}
+
+// download downloads a Gitea release asset content and returns it.
+func (r *GiteaAssetRepository) download(ctx context.Context, asset Asset) (AssetContent, error) {
+	req, err := r.client.newRequest(ctx, asset.downloadURL)
+	if err != nil {
+		return nil, err
+	}
+	return download(r.client.client, req, asset.size, r.progressBar)
+}
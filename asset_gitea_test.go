@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeGiteaServer starts a fake Gitea API server which serves releases of "owner/tool" repository.
+// Release asset download requires token "secret".
+func newFakeGiteaServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	release := func(tag string, body string) map[string]any {
+		return map[string]any{
+			"tag_name": tag,
+			"name":     tag,
+			"body":     body,
+			"assets": []map[string]any{
+				{"id": 10, "name": "tool_linux_amd64", "size": 6, "browser_download_url": server.URL + "/owner/tool/releases/download/" + tag + "/tool_linux_amd64"},
+			},
+		}
+	}
+
+	mux.HandleFunc("GET /api/v1/repos/owner/tool/releases", func(w http.ResponseWriter, r *http.Request) {
+		releases := []map[string]any{release("v2.0.0", "New feature"), release("v1.9.0", "Bug fix")}
+		if r.URL.Query().Get("page") != "1" {
+			releases = nil
+		}
+		json.NewEncoder(w).Encode(releases) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /api/v1/repos/owner/tool/releases/latest", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(release("v2.0.0", "New feature")) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /api/v1/repos/owner/tool/releases/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(release(r.PathValue("tag"), "")) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /owner/tool/releases/download/{tag}/tool_linux_amd64", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, "binary") // nolint:errcheck
+	})
+	return server
+}
+
+func TestGiteaAssetRepository(t *testing.T) {
+	require := require.New(t)
+
+	server := newFakeGiteaServer(t)
+	t.Setenv("FAKE_GITEA_TOKEN", "secret")
+	repo := Repository{host: "codeberg.example.com", owner: "owner", name: "tool"}
+	config := Config{
+		Hosts: map[string]HostConfig{
+			"codeberg.example.com": {Type: "gitea", APIURL: server.URL + "/api/v1", TokenEnv: "FAKE_GITEA_TOKEN"},
+		},
+	}
+	ctx := context.Background()
+
+	r, err := newAssetRepository("codeberg.example.com/owner/tool", config, io.Discard)
+	require.NoError(err)
+	assets, err := r.list(ctx, Release{tag: "v2.0.0"})
+	require.NoError(err)
+	require.Equal([]Asset{{id: 10, downloadURL: must(url.Parse(server.URL + "/owner/tool/releases/download/v2.0.0/tool_linux_amd64")), size: 6}}, assets)
+
+	content, err := r.download(ctx, assets[0])
+	require.NoError(err)
+	require.Equal(AssetContent("binary"), content)
+
+	releaseRepository, err := newReleaseRepository(repo, config)
+	require.NoError(err)
+
+	latest, err := releaseRepository.latest(ctx)
+	require.NoError(err)
+	require.Equal(Release{tag: "v2.0.0"}, latest)
+
+	notes, err := releaseRepository.notes(ctx, Release{tag: "v1.8.0"}, latest)
+	require.NoError(err)
+	require.Len(notes, 2)
+	require.Equal("v2.0.0", notes[0].release.tag)
+	require.Equal("v1.9.0", notes[1].release.tag)
+}
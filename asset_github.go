@@ -2,30 +2,36 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 
-	"github.com/cheggaaa/pb/v3"
-	"github.com/cli/go-gh/v2/pkg/auth"
 	"github.com/google/go-github/v67/github"
 )
 
 // GitHubAssetRepository is a repository for [Asset] and [AssetContent].
 type GitHubAssetRepository struct {
 	client      *github.Client
+	httpClient  *http.Client // HTTP client to download content, which is redirected to by API.
+	token       *GitHubToken
 	repo        Repository
 	progressBar io.Writer // written progress bar into when downloading a GitHub release asset.
 }
 
 // newGitHubAssetRepository returns a new [GitHubAssetRepository] object.
-func newGitHubAssetRepository(repo Repository, progressBar io.Writer) *GitHubAssetRepository {
-	token, _ := auth.TokenForHost(repo.host)
+func newGitHubAssetRepository(repo Repository, host HostConfig, progressBar io.Writer) (*GitHubAssetRepository, error) {
+	client, token, err := newGitHubClient(repo, host)
+	if err != nil {
+		return nil, err
+	}
 	return &GitHubAssetRepository{
-		client:      github.NewClient(http.DefaultClient).WithAuthToken(token),
+		client:      client,
+		httpClient:  host.httpClient(),
+		token:       token,
 		repo:        repo,
 		progressBar: progressBar,
-	}
+	}, nil
 }
 
 // list lists GitHub release assets in a given GitHub release and returns them.
@@ -34,7 +40,7 @@ func (r *GitHubAssetRepository) list(ctx context.Context, release Release) ([]As
 
 	repositoryRelease, _, err := r.client.Repositories.GetReleaseByTag(ctx, r.repo.owner, r.repo.name, release.tag)
 	if err != nil {
-		return nil, err
+		return nil, r.token.explain(err)
 	}
 
 	for page := 1; page != 0; {
@@ -42,7 +48,7 @@ func (r *GitHubAssetRepository) list(ctx context.Context, release Release) ([]As
 			Page: page,
 		})
 		if err != nil {
-			return nil, err
+			return nil, r.token.explain(err)
 		}
 		for _, releaseAsset := range releaseAssets {
 			downloadURL, err := url.Parse(releaseAsset.GetBrowserDownloadURL())
@@ -52,6 +58,7 @@ func (r *GitHubAssetRepository) list(ctx context.Context, release Release) ([]As
 			assets = append(assets, Asset{
 				id:          releaseAsset.GetID(),
 				downloadURL: downloadURL,
+				size:        int64(releaseAsset.GetSize()),
 			})
 		}
 		page = resp.NextPage
@@ -61,21 +68,29 @@ func (r *GitHubAssetRepository) list(ctx context.Context, release Release) ([]As
 }
 
 // download downloads a GitHub release asset content and returns it.
+// Asset is downloaded through API with access token so that assets in private repository can be downloaded.
+// API redirects to storage on other origin, where access token is not sent.
 func (r *GitHubAssetRepository) download(ctx context.Context, asset Asset) (AssetContent, error) {
-	releaseAsset, _, err := r.client.Repositories.GetReleaseAsset(ctx, r.repo.owner, r.repo.name, asset.id)
+	u, err := r.client.BaseURL.Parse(fmt.Sprintf("repos/%s/%s/releases/assets/%d", r.repo.owner, r.repo.name, asset.id))
 	if err != nil {
 		return nil, err
 	}
-
-	rc, _, err := r.client.Repositories.DownloadReleaseAsset(ctx, r.repo.owner, r.repo.name, asset.id, http.DefaultClient)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
-	defer rc.Close() // nolint:errcheck
-
-	total := int64(releaseAsset.GetSize())
-	pr := pb.Full.Start64(total).SetWriter(r.progressBar).NewProxyReader(rc)
-	defer pr.Close() // nolint:errcheck
+	req.Header.Set("Accept", "application/octet-stream")
+	token, err := r.token.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
 
-	return io.ReadAll(pr)
+	b, err := download(r.httpClient, req, asset.size, r.progressBar)
+	if err != nil {
+		return nil, r.token.explain(err)
+	}
+	return b, nil
 }
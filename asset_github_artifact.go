@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// WorkflowRunSelector selects a GitHub Actions workflow run whose artifacts are installed from.
+// Exactly one of runID, pullRequest and branch should be set. workflow narrows down workflow runs for pullRequest or branch.
+type WorkflowRunSelector struct {
+	runID       int64
+	pullRequest int
+	branch      string
+	workflow    string
+}
+
+// selected returns true if any workflow run is selected.
+func (s WorkflowRunSelector) selected() bool {
+	return s.runID != 0 || s.pullRequest != 0 || s.branch != ""
+}
+
+// WorkflowRun represents a GitHub Actions workflow run.
+type WorkflowRun struct {
+	id      int64
+	branch  string
+	headSHA string
+	htmlURL string
+}
+
+// tag returns pseudo release tag of workflow run, which is used in place of release tag to install and record executable binary.
+func (r WorkflowRun) tag() string {
+	return fmt.Sprintf("run-%d", r.id)
+}
+
+// String returns human readable description of workflow run.
+func (r WorkflowRun) String() string {
+	sha := r.headSHA
+	if len(sha) > 7 {
+		sha = sha[:7]
+	}
+	return fmt.Sprintf("workflow run %d on %s@%s (%s)", r.id, r.branch, sha, r.htmlURL)
+}
+
+// GitHubArtifactAssetRepository is a repository for [Asset] and [AssetContent] uploaded as artifacts of a GitHub Actions workflow run.
+// Each artifact is listed as [Asset] whose download URL is "https://HOST/OWNER/REPO/actions/runs/RUN/artifacts/ID/NAME.zip"
+// so that patterns can match artifact name.
+type GitHubArtifactAssetRepository struct {
+	client      *github.Client
+	httpClient  *http.Client // HTTP client to download content, which is redirected to by API.
+	token       *GitHubToken
+	repo        Repository
+	run         WorkflowRun
+	progressBar io.Writer // written progress bar into when downloading an artifact.
+}
+
+// newGitHubArtifactAssetRepository returns a new [GitHubArtifactAssetRepository] object for given workflow run.
+func newGitHubArtifactAssetRepository(repo Repository, host HostConfig, run WorkflowRun, progressBar io.Writer) (*GitHubArtifactAssetRepository, error) {
+	client, token, err := newGitHubClient(repo, host)
+	if err != nil {
+		return nil, err
+	}
+	return &GitHubArtifactAssetRepository{
+		client:      client,
+		httpClient:  host.httpClient(),
+		token:       token,
+		repo:        repo,
+		run:         run,
+		progressBar: progressBar,
+	}, nil
+}
+
+// findWorkflowRun returns workflow run selected by given selector.
+// If pull request or branch is selected, the latest successful workflow run for it is returned.
+func findWorkflowRun(ctx context.Context, client *github.Client, repo Repository, selector WorkflowRunSelector) (WorkflowRun, error) {
+	if selector.runID != 0 {
+		run, _, err := client.Actions.GetWorkflowRunByID(ctx, repo.owner, repo.name, selector.runID)
+		if err != nil {
+			return WorkflowRun{}, err
+		}
+		return newWorkflowRun(run), nil
+	}
+
+	opts := &github.ListWorkflowRunsOptions{
+		Branch: selector.branch,
+		Status: "success",
+	}
+	target := fmt.Sprintf("branch %s", selector.branch)
+	if selector.pullRequest != 0 {
+		pr, _, err := client.PullRequests.Get(ctx, repo.owner, repo.name, selector.pullRequest)
+		if err != nil {
+			return WorkflowRun{}, err
+		}
+		opts = &github.ListWorkflowRunsOptions{
+			HeadSHA: pr.GetHead().GetSHA(),
+			Status:  "success",
+		}
+		target = fmt.Sprintf("pull request #%d", selector.pullRequest)
+	}
+
+	var (
+		runs *github.WorkflowRuns
+		err  error
+	)
+	if selector.workflow != "" {
+		runs, _, err = client.Actions.ListWorkflowRunsByFileName(ctx, repo.owner, repo.name, selector.workflow, opts)
+		target = fmt.Sprintf("workflow %s for %s", selector.workflow, target)
+	} else {
+		runs, _, err = client.Actions.ListRepositoryWorkflowRuns(ctx, repo.owner, repo.name, opts)
+	}
+	if err != nil {
+		return WorkflowRun{}, err
+	}
+	if len(runs.WorkflowRuns) == 0 {
+		return WorkflowRun{}, fmt.Errorf("no successful workflow run was found for %s", target)
+	}
+	return newWorkflowRun(runs.WorkflowRuns[0]), nil
+}
+
+// newWorkflowRun returns a new [WorkflowRun] object from GitHub API response.
+func newWorkflowRun(run *github.WorkflowRun) WorkflowRun {
+	return WorkflowRun{
+		id:      run.GetID(),
+		branch:  run.GetHeadBranch(),
+		headSHA: run.GetHeadSHA(),
+		htmlURL: run.GetHTMLURL(),
+	}
+}
+
+// list lists unexpired artifacts of workflow run and returns them. Given release is ignored because artifacts don't belong to any release.
+func (r *GitHubArtifactAssetRepository) list(ctx context.Context, _ Release) ([]Asset, error) {
+	assets := []Asset{}
+
+	for page := 1; page != 0; {
+		artifacts, resp, err := r.client.Actions.ListWorkflowRunArtifacts(ctx, r.repo.owner, r.repo.name, r.run.id, &github.ListOptions{
+			Page: page,
+		})
+		if err != nil {
+			return nil, r.token.explain(err)
+		}
+		for _, artifact := range artifacts.Artifacts {
+			if artifact.GetExpired() {
+				continue
+			}
+			downloadURL, err := url.Parse(fmt.Sprintf("https://%s/%s/%s/actions/runs/%d/artifacts/%d/%s.zip", r.repo.host, r.repo.owner, r.repo.name, r.run.id, artifact.GetID(), url.PathEscape(artifact.GetName())))
+			if err != nil {
+				return nil, err
+			}
+			assets = append(assets, Asset{
+				id:          artifact.GetID(),
+				downloadURL: downloadURL,
+				size:        artifact.GetSizeInBytes(),
+			})
+		}
+		page = resp.NextPage
+	}
+
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("%s has no unexpired artifacts", r.run)
+	}
+	return assets, nil
+}
+
+// download downloads an artifact as zip file and returns it.
+func (r *GitHubArtifactAssetRepository) download(ctx context.Context, asset Asset) (AssetContent, error) {
+	location, _, err := r.client.Actions.DownloadArtifact(ctx, r.repo.owner, r.repo.name, asset.id, 1)
+	if err != nil {
+		return nil, r.token.explain(err)
+	}
+	if location == nil {
+		return nil, errors.New("download URL of artifact was not returned")
+	}
+
+	// Artifact is served from signed URL on other origin, so authorization header must not be sent there.
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return download(r.httpClient, req, asset.size, r.progressBar)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeGitHubActionsServer starts a fake GitHub API server which serves workflow runs and their artifacts of "owner/tool" repository,
+// and returns a GitHub API client for it.
+func newFakeGitHubActionsServer(t *testing.T) *github.Client {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, err := zw.Create("tool")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("\x00binary"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	run := func(id int64, branch string, sha string) map[string]any {
+		return map[string]any{"id": id, "head_branch": branch, "head_sha": sha, "html_url": "https://github.com/owner/tool/actions/runs/1"}
+	}
+	mux.HandleFunc("GET /repos/owner/tool/actions/runs/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(run(1, "main", "aaaaaaaaaa")) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /repos/owner/tool/actions/runs", func(w http.ResponseWriter, r *http.Request) {
+		runs := []map[string]any{}
+		if r.URL.Query().Get("status") == "success" && r.URL.Query().Get("head_sha") == "bbbbbbbbbb" {
+			runs = append(runs, run(2, "fix", "bbbbbbbbbb"))
+		}
+		json.NewEncoder(w).Encode(map[string]any{"total_count": len(runs), "workflow_runs": runs}) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /repos/owner/tool/actions/workflows/build.yml/runs", func(w http.ResponseWriter, r *http.Request) {
+		runs := []map[string]any{}
+		if r.URL.Query().Get("status") == "success" && r.URL.Query().Get("branch") == "main" {
+			runs = append(runs, run(3, "main", "cccccccccc"), run(1, "main", "aaaaaaaaaa"))
+		}
+		json.NewEncoder(w).Encode(map[string]any{"total_count": len(runs), "workflow_runs": runs}) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /repos/owner/tool/pulls/10", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"number": 10, "head": map[string]any{"sha": "bbbbbbbbbb"}}) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /repos/owner/tool/actions/runs/3/artifacts", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{ // nolint:errcheck
+			"total_count": 2,
+			"artifacts": []map[string]any{
+				{"id": 30, "name": "tool-linux-amd64", "expired": false},
+				{"id": 31, "name": "tool-darwin-arm64", "expired": true},
+			},
+		})
+	})
+	mux.HandleFunc("GET /repos/owner/tool/actions/artifacts/30/zip", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL+"/signed/30.zip", http.StatusFound)
+	})
+	mux.HandleFunc("GET /signed/30.zip", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write(zipped.Bytes()) // nolint:errcheck
+	})
+
+	client := github.NewClient(nil)
+	client.BaseURL = must(url.Parse(server.URL + "/"))
+	return client
+}
+
+func TestFindWorkflowRun(t *testing.T) {
+	client := newFakeGitHubActionsServer(t)
+	repo := Repository{host: "github.com", owner: "owner", name: "tool"}
+
+	tests := []struct {
+		name     string
+		selector WorkflowRunSelector
+		run      int64
+		err      string
+	}{
+		{name: "Run", selector: WorkflowRunSelector{runID: 1}, run: 1},
+		{name: "PullRequest", selector: WorkflowRunSelector{pullRequest: 10}, run: 2},
+		{name: "BranchAndWorkflow", selector: WorkflowRunSelector{branch: "main", workflow: "build.yml"}, run: 3},
+		{name: "NotFound", selector: WorkflowRunSelector{branch: "dev", workflow: "build.yml"}, err: "no successful workflow run was found for workflow build.yml for branch dev"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run, err := findWorkflowRun(context.Background(), client, repo, tt.selector)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.run, run.id)
+			require.Equal(t, fmt.Sprintf("run-%d", tt.run), run.tag())
+		})
+	}
+}
+
+func TestGitHubArtifactAssetRepository(t *testing.T) {
+	require := require.New(t)
+
+	client := newFakeGitHubActionsServer(t)
+	repo := Repository{host: "github.com", owner: "owner", name: "tool"}
+	ctx := context.Background()
+
+	run, err := findWorkflowRun(ctx, client, repo, WorkflowRunSelector{branch: "main", workflow: "build.yml"})
+	require.NoError(err)
+	r, err := newGitHubArtifactAssetRepository(repo, HostConfig{}, run, io.Discard)
+	require.NoError(err)
+	r.client = client
+
+	assets, err := r.list(ctx, Release{tag: run.tag()})
+	require.NoError(err)
+	require.Equal([]Asset{{id: 30, downloadURL: must(url.Parse("https://github.com/owner/tool/actions/runs/3/artifacts/30/tool-linux-amd64.zip"))}}, assets)
+
+	patterns, err := parsePatterns(defaultPatterns)
+	require.NoError(err)
+	asset, pattern, err := findAssetAndPattern(assets, patterns)
+	require.NoError(err)
+	execBinary, err := pattern.execute(Release{tag: run.tag()}, asset, nil)
+	require.NoError(err)
+	require.Equal("tool", execBinary.name)
+
+	content, err := r.download(ctx, asset)
+	require.NoError(err)
+	b, err := content.extract(context.Background(), execBinary, ExtractLimits{})
+	require.NoError(err)
+	require.Equal(ExecBinaryContent("\x00binary"), b)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+// GitLabAssetRepository is a repository for [Asset] and [AssetContent] attached to GitLab releases.
+type GitLabAssetRepository struct {
+	client      *gitLabClient
+	progressBar io.Writer // written progress bar into when downloading a release asset.
+}
+
+// newGitLabAssetRepository returns a new [GitLabAssetRepository] object.
+func newGitLabAssetRepository(repo Repository, host HostConfig, progressBar io.Writer) *GitLabAssetRepository {
+	return &GitLabAssetRepository{
+		client:      newGitLabClient(repo, host),
+		progressBar: progressBar,
+	}
+}
+
+// list lists release asset links in a given GitLab release and returns them.
+// Direct asset URL is preferred to link URL because it is stable.
+// Release links don't tell sizes, so size is looked up in GitLab Packages API only for links to generic packages in same GitLab instance.
+// Size of other links is left unknown.
+func (r *GitLabAssetRepository) list(ctx context.Context, release Release) ([]Asset, error) {
+	gr, err := r.client.release(ctx, release.tag)
+	if err != nil {
+		return nil, err
+	}
+	packages := map[[2]string]map[string]int64{} // file sizes keyed by package name and version.
+	assets := []Asset{}
+	for _, link := range gr.Assets.Links {
+		s := link.URL
+		if link.DirectAssetURL != "" {
+			s = link.DirectAssetURL
+		}
+		downloadURL, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		var size int64
+		if name, version, file, ok := r.client.genericPackageFile(downloadURL); ok {
+			key := [2]string{name, version}
+			if _, ok := packages[key]; !ok {
+				packages[key], _ = r.client.packageFileSizes(ctx, name, version) // Size is optional and left unknown if it can't be looked up.
+			}
+			size = packages[key][file]
+		}
+		assets = append(assets, Asset{
+			id:          link.ID,
+			downloadURL: downloadURL,
+			size:        size,
+		})
+	}
+	return assets, nil
+}
+
+// download downloads a GitLab release asset content and returns it.
+func (r *GitLabAssetRepository) download(ctx context.Context, asset Asset) (AssetContent, error) {
+	req, err := r.client.newRequest(ctx, asset.downloadURL)
+	if err != nil {
+		return nil, err
+	}
+	return download(r.client.client, req, asset.size, r.progressBar)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeGitLabServer starts a fake GitLab API server which serves releases of "group/tool" project.
+// Generic package download requires token "secret".
+func newFakeGitLabServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	releases := []map[string]any{
+		{"tag_name": "v1.1.0", "name": "v1.1.0", "description": "BREAKING: renamed flag", "upcoming_release": false},
+		{"tag_name": "v1.0.0", "name": "v1.0.0", "description": "First release", "upcoming_release": false},
+	}
+
+	mux.HandleFunc("GET /api/v4/projects/group%2Ftool/releases", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(releases) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /api/v4/projects/group%2Ftool/releases/v1.1.0", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{ // nolint:errcheck
+			"tag_name": "v1.1.0",
+			"assets": map[string]any{
+				"links": []map[string]any{
+					{
+						"id":               1,
+						"name":             "tool_linux_amd64",
+						"url":              server.URL + "/-/project/1/uploads/tool_linux_amd64",
+						"direct_asset_url": server.URL + "/api/v4/projects/group%2Ftool/packages/generic/tool/1.1.0/tool_linux_amd64",
+					},
+				},
+			},
+		})
+	})
+	mux.HandleFunc("GET /api/v4/projects/group%2Ftool/packages", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("package_name") != "tool" || r.URL.Query().Get("package_version") != "1.1.0" {
+			json.NewEncoder(w).Encode([]map[string]any{}) // nolint:errcheck
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]any{{"id": 7}}) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /api/v4/projects/group%2Ftool/packages/7/package_files", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{{"file_name": "tool_linux_amd64", "size": 6}}) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /api/v4/projects/group%2Ftool/packages/generic/tool/1.1.0/tool_linux_amd64", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "binary") // nolint:errcheck
+	})
+	return server
+}
+
+func TestGitLabAssetRepository(t *testing.T) {
+	require := require.New(t)
+
+	server := newFakeGitLabServer(t)
+	t.Setenv("FAKE_GITLAB_TOKEN", "secret")
+	repo := Repository{host: "gitlab.example.com", owner: "group", name: "tool"}
+	config := Config{
+		Hosts: map[string]HostConfig{
+			"gitlab.example.com": {Type: "gitlab", APIURL: server.URL + "/api/v4", TokenEnv: "FAKE_GITLAB_TOKEN"},
+		},
+	}
+	host := config.host(repo.host)
+	ctx := context.Background()
+
+	r := newGitLabAssetRepository(repo, host, io.Discard)
+	assets, err := r.list(ctx, Release{tag: "v1.1.0"})
+	require.NoError(err)
+	require.Equal([]Asset{{id: 1, downloadURL: must(url.Parse(server.URL + "/api/v4/projects/group%2Ftool/packages/generic/tool/1.1.0/tool_linux_amd64")), size: 6}}, assets)
+
+	content, err := r.download(ctx, assets[0])
+	require.NoError(err)
+	require.Equal(AssetContent("binary"), content)
+
+	releaseRepository, err := newReleaseRepository(repo, config)
+	require.NoError(err)
+
+	latest, err := releaseRepository.latest(ctx)
+	require.NoError(err)
+	require.Equal(Release{tag: "v1.1.0"}, latest)
+
+	notes, err := releaseRepository.notes(ctx, Release{tag: "v1.0.0"}, latest)
+	require.NoError(err)
+	require.Len(notes, 1)
+	require.True(notes[0].breaking())
+}
+
+func TestParseRepositoryNestedNamespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    string
+		want    Repository
+		wantErr bool
+	}{
+		{
+			name: "Subgroup",
+			repo: "gitlab.example.com/group/sub/tool",
+			want: Repository{host: "gitlab.example.com", owner: "group/sub", name: "tool"},
+		},
+		{
+			name: "URL",
+			repo: "https://gitlab.example.com/group/sub/sub2/tool.git",
+			want: Repository{host: "gitlab.example.com", owner: "group/sub/sub2", name: "tool"},
+		},
+		{
+			name:    "EmptySegment",
+			repo:    "gitlab.example.com/group//tool",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := parseRepository(tt.repo)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, repo)
+		})
+	}
+}
+
+func TestNestedNamespaceOnlyForGitLab(t *testing.T) {
+	config := Config{
+		Hosts: map[string]HostConfig{
+			"gitlab.example.com": {Type: "gitlab"},
+			"gitea.example.com":  {Type: "gitea"},
+		},
+	}
+
+	_, err := newSourceAssetRepository("gitlab.example.com/group/sub/tool", config, io.Discard)
+	require.NoError(t, err)
+	_, err = newSourceAssetRepository("gitea.example.com/group/sub/tool", config, io.Discard)
+	require.ErrorContains(t, err, "nested namespace is supported only by GitLab host")
+	_, err = newReleaseRepository(Repository{host: "github.com", owner: "group/sub", name: "tool"}, config)
+	require.ErrorContains(t, err, "nested namespace is supported only by GitLab host")
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+)
+
+// MirrorAssetRepository is a repository for [Asset] and [AssetContent] which downloads release assets from mirrors.
+// Release assets are listed by underlying repository as is, so patterns match their original download URL.
+// Release asset whose download URL matches mirror rule is downloaded from rewritten URL without credentials.
+// Other release assets are downloaded by underlying repository.
+type MirrorAssetRepository struct {
+	base        AssetRepository
+	client      *http.Client
+	mirrors     []MirrorConfig
+	progressBar io.Writer // written progress bar into when downloading a release asset from mirror.
+}
+
+// newMirrorAssetRepository returns given [AssetRepository] wrapped to download release assets from mirrors configured in configuration.
+// If no mirror rules are configured, this returns given [AssetRepository] as is.
+func newMirrorAssetRepository(r AssetRepository, config Config, progressBar io.Writer) AssetRepository {
+	if len(config.HTTP.Mirrors) == 0 {
+		return r
+	}
+	return &MirrorAssetRepository{
+		base:        r,
+		client:      config.httpClient(),
+		mirrors:     config.HTTP.Mirrors,
+		progressBar: progressBar,
+	}
+}
+
+// list lists release assets by underlying repository and returns them.
+func (r *MirrorAssetRepository) list(ctx context.Context, release Release) ([]Asset, error) {
+	return r.base.list(ctx, release)
+}
+
+// download downloads a release asset content from mirror and returns it.
+func (r *MirrorAssetRepository) download(ctx context.Context, asset Asset) (AssetContent, error) {
+	u, ok, err := rewrite(asset.downloadURL, r.mirrors)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return r.base.download(ctx, asset)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return download(r.client, req, asset.size, r.progressBar)
+}
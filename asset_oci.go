@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// OCIAssetRepository is a repository for [Asset] and [AssetContent] published as layers of OCI artifact.
+// Each layer for [defaultPlatform] is listed as [Asset] whose download URL is "oci://REGISTRY/NAMESPACE/REPO@DIGEST/TITLE".
+type OCIAssetRepository struct {
+	client      *ociClient
+	mediaType   string    // media type of layers to list. All layers are listed if this is empty.
+	progressBar io.Writer // written progress bar into when downloading a layer.
+}
+
+// newOCIAssetRepository returns a new [OCIAssetRepository] object.
+func newOCIAssetRepository(repo Repository, host HostConfig, mediaType string, progressBar io.Writer) *OCIAssetRepository {
+	return &OCIAssetRepository{
+		client:      newOCIClient(repo, host),
+		mediaType:   mediaType,
+		progressBar: progressBar,
+	}
+}
+
+// list lists layers of OCI artifact which has given tag for [defaultPlatform] and returns them.
+// File name of layer is taken from "org.opencontainers.image.title" annotation. If it doesn't exist, repository name is used instead.
+func (r *OCIAssetRepository) list(ctx context.Context, release Release) ([]Asset, error) {
+	m, err := r.client.platformManifest(ctx, release.tag, defaultPlatform)
+	if err != nil {
+		return nil, err
+	}
+	assets := []Asset{}
+	for _, layer := range m.Layers {
+		if r.mediaType != "" && layer.MediaType != r.mediaType {
+			continue
+		}
+		title := layer.Annotations[ociTitleAnnotation]
+		if title == "" {
+			title = r.client.repo.name
+		}
+		downloadURL, err := url.Parse(fmt.Sprintf("%s@%s/%s", r.client.repo, layer.Digest, url.PathEscape(title)))
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, Asset{
+			downloadURL: downloadURL,
+		})
+	}
+	return assets, nil
+}
+
+// download downloads layer of OCI artifact, verifies its digest and returns it.
+func (r *OCIAssetRepository) download(ctx context.Context, asset Asset) (AssetContent, error) {
+	_, rest, ok := strings.Cut(asset.downloadURL.Path, "@")
+	if !ok {
+		return nil, fmt.Errorf("download URL of OCI layer was invalid: %s", asset.downloadURL)
+	}
+	digest, _, _ := strings.Cut(rest, "/")
+	return r.client.blob(ctx, digest, r.progressBar)
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ociDigest returns sha256 digest of b in OCI format.
+func ociDigest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// newFakeOCIRegistry starts a fake OCI registry which serves "owner/tool" repository.
+// Each request requires bearer token "secret" which is issued by token service of the registry.
+// Blob of "linux/amd64" layer is served as is, while blob of "darwin/arm64" layer is corrupted.
+func newFakeOCIRegistry(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	blobs := map[string][]byte{}
+	manifests := map[string][]byte{}
+	put := func(m map[string][]byte, b []byte) string {
+		d := ociDigest(b)
+		m[d] = b
+		return d
+	}
+	manifest := func(os string, arch string, content string) map[string]any {
+		d := put(blobs, []byte(content))
+		if os == "darwin" {
+			blobs[d] = []byte("corrupted")
+		}
+		b, err := json.Marshal(map[string]any{
+			"mediaType": ociImageManifestMediaType,
+			"layers": []map[string]any{
+				{"mediaType": "application/vnd.example.readme", "digest": put(blobs, []byte("readme")), "size": 6, "annotations": map[string]string{ociTitleAnnotation: "README.md"}},
+				{"mediaType": "application/vnd.example.binary", "digest": d, "size": len(content), "annotations": map[string]string{ociTitleAnnotation: "tool_" + os + "_" + arch}},
+			},
+		})
+		require.NoError(t, err)
+		return map[string]any{"mediaType": ociImageManifestMediaType, "digest": put(manifests, b), "platform": map[string]string{"os": os, "architecture": arch}}
+	}
+	index, err := json.Marshal(map[string]any{
+		"mediaType": ociImageIndexMediaType,
+		"manifests": []map[string]any{manifest("darwin", "arm64", "darwin binary"), manifest("linux", "amd64", "linux binary")},
+	})
+	require.NoError(t, err)
+	manifests["v1.0.0"] = index
+
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+	mux.HandleFunc("GET /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("service") != "fake" || r.URL.Query().Get("scope") != "repository:owner/tool:pull" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"token": "secret"}) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /v2/owner/tool/manifests/{reference}", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		b, ok := manifests[r.PathValue("reference")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(b) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /v2/owner/tool/blobs/{digest}", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		b, ok := blobs[r.PathValue("digest")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(b) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /v2/owner/tool/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		// Tags are listed in pages of two tags as registries such as Docker Hub do.
+		tags := []string{"v0.9.0", "latest", "v1.1.0-rc.1", "v1.0.0"}
+		start := 0
+		if last := r.URL.Query().Get("last"); last != "" {
+			start = slices.Index(tags, last) + 1
+		}
+		end := min(start+2, len(tags))
+		if end < len(tags) {
+			w.Header().Set("Link", fmt.Sprintf(`</v2/owner/tool/tags/list?n=2&last=%s>; rel="next"`, tags[end-1]))
+		}
+		json.NewEncoder(w).Encode(map[string]any{"name": "owner/tool", "tags": tags[start:end]}) // nolint:errcheck
+	})
+	return server
+}
+
+func TestOCIAssetRepository(t *testing.T) {
+	require := require.New(t)
+
+	server := newFakeOCIRegistry(t)
+	ref := "oci://" + strings.TrimPrefix(server.URL, "http://") + "/owner/tool:v1.0.0"
+	repo, tag, err := parseOCIReference(ref)
+	require.NoError(err)
+	require.Equal("v1.0.0", tag)
+	ctx := context.Background()
+
+	r, err := newAssetRepository(ref, Config{}, io.Discard)
+	require.NoError(err)
+	assets, err := r.list(ctx, Release{tag: tag})
+	require.NoError(err)
+	require.Len(assets, 2)
+	require.Equal(fmt.Sprintf("%s@%s/tool_linux_amd64", repo, ociDigest([]byte("linux binary"))), assets[1].downloadURL.String())
+
+	patterns, err := parsePatterns(defaultPatterns)
+	require.NoError(err)
+	asset, pattern, err := findAssetAndPattern(assets, patterns)
+	require.NoError(err)
+	execBinary, err := pattern.execute(Release{tag: tag}, asset, nil)
+	require.NoError(err)
+	require.Equal("tool", execBinary.name)
+
+	content, err := r.download(ctx, asset)
+	require.NoError(err)
+	require.Equal(AssetContent("linux binary"), content)
+
+	r, err = newAssetRepository(ref, Config{Tools: map[string]ToolConfig{repo.String(): {MediaType: "application/vnd.example.binary"}}}, io.Discard)
+	require.NoError(err)
+	assets, err = r.list(ctx, Release{tag: tag})
+	require.NoError(err)
+	require.Len(assets, 1)
+
+	releaseRepository, err := newReleaseRepository(repo, Config{})
+	require.NoError(err)
+	latest, err := releaseRepository.latest(ctx)
+	require.NoError(err)
+	require.Equal(Release{tag: "v1.0.0"}, latest)
+}
+
+func TestOCIClientBlobDigestMismatch(t *testing.T) {
+	server := newFakeOCIRegistry(t)
+	repo, _, err := parseOCIReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/owner/tool")
+	require.NoError(t, err)
+	client := newOCIClient(repo, HostConfig{})
+
+	_, err = client.blob(context.Background(), ociDigest([]byte("darwin binary")), io.Discard)
+	require.ErrorContains(t, err, "digest of blob was mismatched")
+}
+
+func TestOCIClientTags(t *testing.T) {
+	server := newFakeOCIRegistry(t)
+	repo, _, err := parseOCIReference("oci://" + strings.TrimPrefix(server.URL, "http://") + "/owner/tool")
+	require.NoError(t, err)
+
+	tags, err := newOCIClient(repo, HostConfig{}).tags(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"v0.9.0", "latest", "v1.1.0-rc.1", "v1.0.0"}, tags)
+}
+
+func TestParseOCIReference(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  string
+		repo Repository
+		tag  string
+	}{
+		{
+			name: "Tag",
+			ref:  "oci://ghcr.io/fluxcd/flux-cli:v2.3.0",
+			repo: Repository{scheme: "oci", host: "ghcr.io", owner: "fluxcd", name: "flux-cli"},
+			tag:  "v2.3.0",
+		},
+		{
+			name: "Digest",
+			ref:  "oci://ghcr.io/fluxcd/flux-cli@sha256:0123456789abcdef",
+			repo: Repository{scheme: "oci", host: "ghcr.io", owner: "fluxcd", name: "flux-cli"},
+			tag:  "sha256:0123456789abcdef",
+		},
+		{
+			name: "RegistryPort",
+			ref:  "oci://localhost:5000/owner/tool",
+			repo: Repository{scheme: "oci", host: "localhost:5000", owner: "owner", name: "tool"},
+			tag:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, tag, err := parseOCIReference(tt.ref)
+			require.NoError(t, err)
+			require.Equal(t, tt.repo, repo)
+			require.Equal(t, tt.tag, tag)
+
+			config := Config{Tools: map[string]ToolConfig{tt.repo.String(): {MediaType: "application/vnd.example.binary"}}}
+			require.Equal(t, "application/vnd.example.binary", config.tool(tt.ref).MediaType)
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// gzipped returns given bytes compressed by gzip.
+func gzipped(t testing.TB, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(b)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestAssetContentExtractLimits(t *testing.T) {
+	binary := make([]byte, 4<<20)
+
+	tests := []struct {
+		name    string
+		content []byte
+		limits  ExtractLimits
+		code    ErrorCode
+	}{
+		{
+			name:    "WithinLimits",
+			content: gzipped(t, gzipped(t, binary)),
+			limits:  ExtractLimits{maxRatio: 10000},
+		},
+		{
+			name:    "NestingTooDeep",
+			content: gzipped(t, gzipped(t, gzipped(t, binary))),
+			limits:  ExtractLimits{maxDepth: 2, maxRatio: 10000},
+			code:    ErrNestingTooDeep,
+		},
+		{
+			name:    "TooLarge",
+			content: gzipped(t, binary),
+			limits:  ExtractLimits{maxSize: 1 << 20, maxRatio: 10000},
+			code:    ErrExtractTooLarge,
+		},
+		{
+			name:    "CompressionRatioExceeded",
+			content: gzipped(t, binary),
+			limits:  ExtractLimits{maxRatio: 10},
+			code:    ErrCompressionRatio,
+		},
+		{
+			name:    "Timeout",
+			content: gzipped(t, binary),
+			limits:  ExtractLimits{maxRatio: 10000, timeout: time.Nanosecond},
+			code:    ErrExtractTimeout,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := AssetContent(tt.content).extract(context.Background(), ExecBinary{name: "tool"}, tt.limits)
+			if tt.code == "" {
+				require.NoError(t, err)
+				require.Equal(t, ExecBinaryContent(binary), b)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tt.code, errorCode(err))
+		})
+	}
+}
+
+// readLimited reads given reader within default limits so that fuzz tests don't exhaust memory.
+func readLimited(r io.ReadCloser, size int) {
+	_, _ = io.ReadAll(defaultExtractLimits.reader(context.Background(), r, int64(size)))
+	_ = r.Close()
+}
+
+func FuzzNewReaderToExtract(f *testing.F) {
+	f.Add(gzipped(f, []byte("\x7fELF")))
+	f.Add([]byte("\xfd7zXZ\x00\x00"))
+	f.Add([]byte("plain text"))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r, err := newReaderToExtract(b, ExecBinary{name: "tool"})
+		if err != nil {
+			return
+		}
+		readLimited(r, len(b))
+	})
+}
+
+func FuzzNewTarReader(f *testing.F) {
+	var tarball bytes.Buffer
+	tw := tar.NewWriter(&tarball)
+	require.NoError(f, tw.WriteHeader(&tar.Header{Name: "bin/tool", Mode: 0755, Size: 4, Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte("tool"))
+	require.NoError(f, err)
+	require.NoError(f, tw.Close())
+	f.Add(tarball.Bytes())
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r, err := newTarReader(bytes.NewReader(b), "tool")
+		if err != nil {
+			return
+		}
+		readLimited(r, len(b))
+	})
+}
+
+func FuzzNewZipReader(f *testing.F) {
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, err := zw.Create("bin/tool")
+	require.NoError(f, err)
+	_, err = w.Write([]byte("tool"))
+	require.NoError(f, err)
+	require.NoError(f, zw.Close())
+	f.Add(zipped.Bytes())
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r, err := newZipReader(bytes.NewReader(b), int64(len(b)), "tool")
+		if err != nil {
+			return
+		}
+		readLimited(r, len(b))
+	})
+}
+
+func TestAssetContentExtractLinks(t *testing.T) {
+	binary := string(make([]byte, 64))
+
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		code    ErrorCode
+	}{
+		{
+			name: "SymbolicLink",
+			entries: []tarEntry{
+				{name: "tool-1.2.3/libexec/tool-1.2.3", typeflag: tar.TypeReg, content: binary},
+				{name: "tool-1.2.3/bin/tool", typeflag: tar.TypeSymlink, linkname: "../libexec/tool-1.2.3"},
+			},
+		},
+		{
+			name: "SymbolicLinkBeforeTarget",
+			entries: []tarEntry{
+				{name: "./bin/tool", typeflag: tar.TypeSymlink, linkname: "../libexec/tool-1.2.3"},
+				{name: "./libexec/tool-1.2.3", typeflag: tar.TypeReg, content: binary},
+			},
+		},
+		{
+			name: "HardLink",
+			entries: []tarEntry{
+				{name: "tool-1.2.3/libexec/tool-1.2.3", typeflag: tar.TypeReg, content: binary},
+				{name: "tool-1.2.3/bin/tool", typeflag: tar.TypeLink, linkname: "tool-1.2.3/libexec/tool-1.2.3"},
+			},
+		},
+		{
+			name: "SymbolicLinkToDirectory",
+			entries: []tarEntry{
+				{name: "versions/1.2.3/tool", typeflag: tar.TypeReg, content: binary},
+				{name: "current", typeflag: tar.TypeSymlink, linkname: "versions/1.2.3"},
+				{name: "bin/tool", typeflag: tar.TypeSymlink, linkname: "../current/tool"},
+			},
+		},
+		{
+			name:    "AbsoluteSymbolicLink",
+			entries: []tarEntry{{name: "bin/tool", typeflag: tar.TypeSymlink, linkname: "/usr/bin/tool"}},
+			code:    ErrUnsafeArchive,
+		},
+		{
+			name:    "SymbolicLinkOutsideArchive",
+			entries: []tarEntry{{name: "bin/tool", typeflag: tar.TypeSymlink, linkname: "../../tool"}},
+			code:    ErrUnsafeArchive,
+		},
+		{
+			name:    "HardLinkOutsideArchive",
+			entries: []tarEntry{{name: "bin/tool", typeflag: tar.TypeLink, linkname: "../tool"}},
+			code:    ErrUnsafeArchive,
+		},
+		{
+			name: "SymbolicLinkLoop",
+			entries: []tarEntry{
+				{name: "bin/tool", typeflag: tar.TypeSymlink, linkname: "tool2"},
+				{name: "bin/tool2", typeflag: tar.TypeSymlink, linkname: "tool"},
+			},
+			code: ErrUnsafeArchive,
+		},
+		{
+			name:    "DanglingSymbolicLink",
+			entries: []tarEntry{{name: "bin/tool", typeflag: tar.TypeSymlink, linkname: "missing"}},
+			code:    ErrExecBinaryNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := newTarball(t, tt.entries).extract(context.Background(), ExecBinary{name: "tool"}, ExtractLimits{})
+			if tt.code != "" {
+				require.Error(t, err)
+				require.Equal(t, tt.code, errorCode(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, ExecBinaryContent(binary), b)
+		})
+	}
+}
+
+func TestNewZipReaderSymlink(t *testing.T) {
+	zipped := func(target string) []byte {
+		var b bytes.Buffer
+		zw := zip.NewWriter(&b)
+		w, err := zw.Create("libexec/tool-1.2.3")
+		require.NoError(t, err)
+		_, err = w.Write([]byte("tool"))
+		require.NoError(t, err)
+		header := &zip.FileHeader{Name: "bin/tool"}
+		header.SetMode(fs.ModeSymlink | 0777)
+		w, err = zw.CreateHeader(header)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(target))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		return b.Bytes()
+	}
+
+	b := zipped("../libexec/tool-1.2.3")
+	r, err := newZipReader(bytes.NewReader(b), int64(len(b)), "tool")
+	require.NoError(t, err)
+	content, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "tool", string(content))
+	require.NoError(t, r.Close())
+
+	b = zipped("../../etc/passwd")
+	_, err = newZipReader(bytes.NewReader(b), int64(len(b)), "tool")
+	require.Error(t, err)
+	require.Equal(t, ErrUnsafeArchive, errorCode(err))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func infoE(name string, dir string, statePath string) error {
+	records, err := findInstallRecords(newStateRepository(statePath), name, dir)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("%s was not installed by gh-release-install", name)
+	}
+
+	for i, r := range records {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Name:         %s\n", r.installName())
+		fmt.Printf("Repository:   %s\n", r.Repo)
+		fmt.Printf("Tag:          %s\n", r.Tag)
+		fmt.Printf("Asset:        %s\n", r.AssetURL)
+		fmt.Printf("Path:         %s\n", r.Path)
+		fmt.Printf("Digest:       %s\n", r.Digest)
+		fmt.Printf("Installed at: %s\n", r.InstalledAt.Local().Format(time.DateTime))
+	}
+	return nil
+}
+
+// newInfoCommand returns a new command to show where executable binary installed by this tool came from.
+func newInfoCommand() *cobra.Command {
+	var (
+		dir       string
+		statePath string
+	)
+
+	command := &cobra.Command{
+		Use:   "info <binary>",
+		Short: "Show where an executable binary installed by gh-release-install came from.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return infoE(args[0], dir, statePath)
+		},
+		SilenceUsage: true,
+	}
+
+	command.Flags().StringVarP(&dir, "dir", "D", "", "Directory where executable binary was installed into. (default any directory)")
+	command.Flags().StringVar(&statePath, "state", defaultStatePath(), "Path of file which records of installed executable binaries are stored into.")
+
+	return command
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func listE(statePath string) error {
+	records, err := newStateRepository(statePath).list()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tREPOSITORY\tTAG\tPATH\tINSTALLED AT") // nolint:errcheck
+	for _, r := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.installName(), r.Repo, r.Tag, r.Path, r.InstalledAt.Local().Format(time.DateTime)) // nolint:errcheck
+	}
+	return w.Flush()
+}
+
+// newListCommand returns a new command to list executable binaries installed by this tool.
+func newListCommand() *cobra.Command {
+	var statePath string
+
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "List executable binaries installed by gh-release-install.",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return listE(statePath)
+		},
+		SilenceUsage: true,
+	}
+
+	command.Flags().StringVar(&statePath, "state", defaultStatePath(), "Path of file which records of installed executable binaries are stored into.")
+
+	return command
+}
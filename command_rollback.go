@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func rollbackE(repo string, name string, dir string, store string, statePath string) error {
+	execBinaryRepository, err := findVersionedExecBinaryRepository(repo, dir, store, name)
+	if err != nil {
+		return err
+	}
+
+	execBinary := ExecBinary{name: name, installName: name}
+	release, err := execBinaryRepository.rollback(execBinary)
+	if err != nil {
+		return err
+	}
+	content, err := execBinaryRepository.read(execBinary)
+	if err != nil {
+		return err
+	}
+	if err := saveActiveVersion(newStateRepository(statePath), execBinaryRepository.path(execBinary), release, content); err != nil {
+		return err
+	}
+
+	fmt.Printf("Rolled back %s to %s\n", name, release.tag)
+	return nil
+}
+
+// newRollbackCommand returns a new command to switch executable binary installed with --versioned back to previous version.
+func newRollbackCommand() *cobra.Command {
+	var (
+		repo      string
+		dir       string
+		store     string
+		statePath string
+	)
+
+	command := &cobra.Command{
+		Use:   "rollback <binary>",
+		Short: "Switch an executable binary installed with --versioned back to previous version.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return rollbackE(repo, args[0], dir, store, statePath)
+		},
+		SilenceUsage: true,
+	}
+
+	command.Flags().StringVarP(&repo, "repo", "R", "", "GitHub repository name. This should be [HOST/]OWNER/REPO format. (default looked up from store)")
+	command.Flags().StringVarP(&dir, "dir", "D", ".", "Directory where executable binary was installed into.")
+	command.Flags().StringVar(&store, "store", defaultStore(), "Directory where each version of executable binary is kept.")
+	command.Flags().StringVar(&statePath, "state", defaultStatePath(), "Path of file which records of installed executable binaries are stored into.")
+
+	return command
+}
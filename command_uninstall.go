@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+func uninstallE(name string, dir string, statePath string) error {
+	state := newStateRepository(statePath)
+	records, err := findInstallRecords(state, name, dir)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(records) == 0:
+		return fmt.Errorf("%s was not installed by gh-release-install; refusing to delete it", name)
+	case len(records) > 1:
+		return fmt.Errorf("%s was installed into multiple directories; specify one of them by --dir", name)
+	}
+	record := records[0]
+
+	content, err := os.ReadFile(record.Path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// Executable binary was already deleted by someone else. Only record is removed.
+	case err != nil:
+		return err
+	case digest(content) != record.Digest:
+		return fmt.Errorf("%s was modified after gh-release-install installed it; refusing to delete it", record.Path)
+	default:
+		if err := os.Remove(record.Path); err != nil {
+			return err
+		}
+	}
+
+	if record.Tree != nil {
+		if err := removeTree(state, record); err != nil {
+			return err
+		}
+	}
+
+	skipped, err := removeCompanions(record.Companions)
+	if err != nil {
+		return err
+	}
+	for _, path := range skipped {
+		fmt.Fprintf(os.Stderr, "warning: %s was modified after gh-release-install installed it; keeping it\n", path) // nolint:errcheck
+	}
+
+	if err := state.remove(record); err != nil {
+		return err
+	}
+
+	fmt.Printf("Uninstalled %s from %s\n", name, record.Path)
+	return nil
+}
+
+// newUninstallCommand returns a new command to delete executable binary installed by this tool.
+func newUninstallCommand() *cobra.Command {
+	var (
+		dir       string
+		statePath string
+	)
+
+	command := &cobra.Command{
+		Use:   "uninstall <binary>",
+		Short: "Delete an executable binary installed by gh-release-install.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return uninstallE(args[0], dir, statePath)
+		},
+		SilenceUsage: true,
+	}
+
+	command.Flags().StringVarP(&dir, "dir", "D", "", "Directory where executable binary was installed into. (default any directory)")
+	command.Flags().StringVar(&statePath, "state", defaultStatePath(), "Path of file which records of installed executable binaries are stored into.")
+
+	return command
+}
+
+// removeTree removes links to additional executable binaries and archive tree installed with given record.
+// Tree is kept if other executable binary installed by this tool still links into it.
+func removeTree(state StateRepository, record InstallRecord) error {
+	errs := []error{}
+	for _, p := range record.Tree.linkPaths(filepath.Dir(record.Path)) {
+		errs = append(errs, removeLinkInto(p, record.Tree.Root))
+	}
+	records, err := state.list()
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	if !slices.ContainsFunc(records, func(r InstallRecord) bool {
+		return r.Path != record.Path && r.Tree != nil && r.Tree.Root == record.Tree.Root
+	}) {
+		errs = append(errs, os.RemoveAll(record.Tree.Root))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUninstall(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	state := newStateRepository(statePath)
+
+	installed := filepath.Join(dir, "installed")
+	require.NoError(os.WriteFile(installed, []byte("installed"), 0755))
+	require.NoError(state.save(InstallRecord{Path: installed, Digest: digest([]byte("installed"))}))
+
+	modified := filepath.Join(dir, "modified")
+	require.NoError(os.WriteFile(modified, []byte("modified"), 0755))
+	require.NoError(state.save(InstallRecord{Path: modified, Digest: digest([]byte("original"))}))
+
+	unknown := filepath.Join(dir, "unknown")
+	require.NoError(os.WriteFile(unknown, []byte("unknown"), 0755))
+
+	require.NoError(uninstallE("installed", dir, statePath))
+	require.NoFileExists(installed)
+
+	require.Error(uninstallE("modified", dir, statePath))
+	require.FileExists(modified)
+
+	require.Error(uninstallE("unknown", dir, statePath))
+	require.FileExists(unknown)
+
+	records, err := state.list()
+	require.NoError(err)
+	require.Len(records, 1)
+	require.Equal(modified, records[0].Path)
+}
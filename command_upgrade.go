@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/prompter"
+	"github.com/spf13/cobra"
+)
+
+// exitUpgradesAvailable is an exit status of upgrade command with --check when newer releases exist.
+// This differs from exit status 1 of failures so that scripts can tell them apart.
+const exitUpgradesAvailable = 2
+
+// UpgradesAvailableError is returned by upgrade command with --check when newer releases exist.
+type UpgradesAvailableError struct {
+	count int
+}
+
+// Error implements error.
+func (e *UpgradesAvailableError) Error() string {
+	return fmt.Sprintf("%d executable binaries can be upgraded", e.count)
+}
+
+func upgradeE(ctx context.Context, names []string, check bool, noNotes bool, statePath string, configPath string, verifyTimeout time.Duration) error {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	state := newStateRepository(statePath)
+	upgrades, failed, err := findUpgrades(ctx, state, names, func(repo Repository) (ReleaseRepository, error) {
+		return newReleaseRepository(repo, config)
+	})
+	if err != nil {
+		return err
+	}
+	// Executable binaries which couldn't be checked are reported first and make this fail at the end, without blocking others.
+	var checkErr error
+	for _, err := range failed {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", err) // nolint:errcheck
+	}
+	if len(failed) > 0 {
+		checkErr = fmt.Errorf("failed to check %d executable binaries", len(failed))
+	}
+	if len(upgrades) == 0 {
+		if checkErr != nil {
+			fmt.Println("Other executable binaries are up to date")
+			return checkErr
+		}
+		fmt.Println("All executable binaries are up to date")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tREPOSITORY\tCURRENT\tAVAILABLE") // nolint:errcheck
+	for _, u := range upgrades {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.record.installName(), u.record.Repo, u.record.Tag, u.release.tag) // nolint:errcheck
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if check {
+		if checkErr != nil {
+			return checkErr
+		}
+		return &UpgradesAvailableError{count: len(upgrades)}
+	}
+
+	if !noNotes {
+		fmt.Println()
+		for _, u := range upgrades {
+			printReleaseNotes(ctx, os.Stdout, u.repo, config, Release{tag: u.record.Tag}, u.release)
+		}
+	}
+
+	prompt := fmt.Sprintf("Do you want to upgrade %d executable binaries?", len(upgrades))
+	confirm, err := prompter.New(os.Stdin, os.Stdout, os.Stderr).Confirm(prompt, true)
+	if !confirm || err != nil {
+		return errors.Join(err, checkErr)
+	}
+
+	errs := []error{}
+	for _, u := range upgrades {
+		toolConfig := config.tool(u.record.Repo)
+		opts := InstallOptions{
+			verifyRun:     toolConfig.VerifyRun,
+			verifyVersion: toolConfig.VerifyVersion,
+			verifyTimeout: verifyTimeout,
+		}
+		app, err := u.application(state, config)
+		if err == nil {
+			err = u.upgrade(ctx, app, opts)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to upgrade %s: %w", u.record.installName(), err))
+			continue
+		}
+		fmt.Printf("Upgraded %s from %s to %s\n", u.record.installName(), u.record.Tag, u.release.tag)
+	}
+	return errors.Join(append(errs, checkErr)...)
+}
+
+// newUpgradeCommand returns a new command to upgrade executable binaries installed by this tool to the latest releases.
+func newUpgradeCommand() *cobra.Command {
+	var (
+		all           bool
+		check         bool
+		noNotes       bool
+		statePath     string
+		configPath    string
+		verifyTimeout time.Duration
+	)
+
+	command := &cobra.Command{
+		Use:   "upgrade [--all | <binary>...]",
+		Short: "Upgrade executable binaries installed by gh-release-install to the latest releases.",
+		Args: func(_ *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return errors.New("specify either --all or names of executable binaries")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return upgradeE(cmd.Context(), args, check, noNotes, statePath, configPath, verifyTimeout)
+		},
+		SilenceUsage: true,
+	}
+
+	command.Flags().BoolVar(&all, "all", false, "Upgrade all executable binaries installed by gh-release-install.")
+	command.Flags().BoolVar(&check, "check", false, "Only check whether newer releases exist. Exit with status 2 if they exist.")
+	command.Flags().BoolVar(&noNotes, "no-notes", false, "Don't show release notes before confirming upgrade.")
+	command.Flags().StringVar(&statePath, "state", defaultStatePath(), "Path of file which records of installed executable binaries are stored into.")
+	command.Flags().StringVar(&configPath, "config", defaultConfigPath(), "Path of configuration file.")
+	command.Flags().DurationVar(&verifyTimeout, "verify-timeout", 30*time.Second, "Timeout of verification command configured for each tool.")
+
+	return command
+}
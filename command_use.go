@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func useE(repo string, name string, tag string, dir string, store string, statePath string) error {
+	execBinaryRepository, err := findVersionedExecBinaryRepository(repo, dir, store, name)
+	if err != nil {
+		return err
+	}
+
+	release := Release{tag: tag}
+	execBinary := ExecBinary{name: name, installName: name}
+	if err := execBinaryRepository.use(release, execBinary); err != nil {
+		return err
+	}
+	content, err := execBinaryRepository.read(execBinary)
+	if err != nil {
+		return err
+	}
+	if err := saveActiveVersion(newStateRepository(statePath), execBinaryRepository.path(execBinary), release, content); err != nil {
+		return err
+	}
+
+	fmt.Printf("Switched %s to %s\n", name, tag)
+	return nil
+}
+
+// newUseCommand returns a new command to switch active version of executable binary installed with --versioned.
+func newUseCommand() *cobra.Command {
+	var (
+		repo      string
+		dir       string
+		store     string
+		statePath string
+	)
+
+	command := &cobra.Command{
+		Use:   "use <binary> <tag>",
+		Short: "Switch active version of an executable binary installed with --versioned.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return useE(repo, args[0], args[1], dir, store, statePath)
+		},
+		SilenceUsage: true,
+	}
+
+	command.Flags().StringVarP(&repo, "repo", "R", "", "GitHub repository name. This should be [HOST/]OWNER/REPO format. (default looked up from store)")
+	command.Flags().StringVarP(&dir, "dir", "D", ".", "Directory where executable binary was installed into.")
+	command.Flags().StringVar(&store, "store", defaultStore(), "Directory where each version of executable binary is kept.")
+	command.Flags().StringVar(&statePath, "state", defaultStatePath(), "Path of file which records of installed executable binaries are stored into.")
+
+	return command
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/ulikunitz/xz"
+)
+
+// CompanionFile is a file shipped with executable binary in archive, such as man page, shell completion or license.
+type CompanionFile struct {
+	// name is a path of file in archive.
+	name string
+
+	// dest is a path relative to share directory where file is installed, such as "man/man1/gh.1".
+	dest string
+
+	content []byte
+}
+
+// manPagePattern matches file name of man page such as "gh.1" or "rg.1.gz" and captures its section.
+var manPagePattern = regexp.MustCompile(`^[A-Za-z][\w-]*\.([1-9])(?:\.gz)?$`)
+
+// companionDest returns a path relative to share directory where file at given path in archive should be installed.
+// Man pages go into "man/manN", shell completions go into directories which bash-completion, zsh and fish look up, and licenses go into "licenses/<installName>".
+// This returns false if file is not recognised as companion file.
+func companionDest(name string, installName string) (string, bool) {
+	base := path.Base(name)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	dirs := strings.Split(strings.ToLower(path.Dir(name)), "/")
+	upper := strings.ToUpper(base)
+
+	switch {
+	case strings.HasPrefix(upper, "LICENSE") || strings.HasPrefix(upper, "LICENCE") || strings.HasPrefix(upper, "COPYING"):
+		return path.Join("licenses", installName, base), true
+	case manPagePattern.MatchString(base):
+		return path.Join("man", "man"+manPagePattern.FindStringSubmatch(base)[1], base), true
+	case ext == ".fish":
+		return path.Join("fish", "vendor_completions.d", base), true
+	case ext == ".zsh":
+		return path.Join("zsh", "site-functions", "_"+strings.TrimPrefix(stem, "_")), true
+	case strings.HasPrefix(base, "_") && ext == "" && containsAny(dirs, "complete", "completion", "completions", "autocomplete", "zsh"):
+		return path.Join("zsh", "site-functions", base), true
+	case ext == ".bash" || ext == ".bash-completion":
+		return path.Join("bash-completion", "completions", stem), true
+	case ext == "" && containsAny(dirs, "bash", "bash-completion", "bash_completion"):
+		return path.Join("bash-completion", "completions", base), true
+	default:
+		return "", false
+	}
+}
+
+// containsAny returns true if any of given values is in s.
+func containsAny(s []string, values ...string) bool {
+	return slices.ContainsFunc(s, func(e string) bool {
+		return slices.Contains(values, e)
+	})
+}
+
+// companions returns companion files of given executable binary in archive. This returns no files if asset is not archive.
+// Archive is decompressed and each companion file is read within given limits.
+func (a AssetContent) companions(ctx context.Context, execBinary ExecBinary, limits ExtractLimits) ([]CompanionFile, error) {
+	limits = limits.orDefault()
+	ctx, cancel := limits.withTimeout(ctx)
+	defer cancel()
+	b, err := decompress(ctx, a, limits)
+	if err != nil {
+		return nil, err
+	}
+	switch mimetype.Detect(b).String() {
+	case "application/x-tar":
+		return tarCompanions(ctx, bytes.NewReader(b), execBinary, limits)
+	case "application/zip":
+		return zipCompanions(ctx, bytes.NewReader(b), int64(len(b)), execBinary, limits)
+	default:
+		return []CompanionFile{}, nil
+	}
+}
+
+// decompress decompresses given bytes repeatedly while they are gzip or xz compressed, and returns the result.
+// Each layer is decompressed within given limits.
+func decompress(ctx context.Context, b []byte, limits ExtractLimits) ([]byte, error) {
+	limits = limits.orDefault()
+	ctx, cancel := limits.withTimeout(ctx)
+	defer cancel()
+	for depth := 1; ; depth++ {
+		var r io.Reader
+		var err error
+		switch mimetype.Detect(b).String() {
+		case "application/gzip":
+			r, err = gzip.NewReader(bytes.NewReader(b))
+		case "application/x-xz":
+			r, err = xz.NewReader(bytes.NewReader(b))
+		default:
+			return b, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := limits.checkDepth(depth); err != nil {
+			return nil, err
+		}
+		if b, err = io.ReadAll(limits.reader(ctx, r, int64(len(b)))); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tarCompanions returns companion files of given executable binary in tarball. Each file is read within given limits.
+func tarCompanions(ctx context.Context, r io.Reader, execBinary ExecBinary, limits ExtractLimits) ([]CompanionFile, error) {
+	files := []CompanionFile{}
+	for tr := tar.NewReader(r); ; {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		dest, ok := companionDest(header.Name, execBinary.installName)
+		if !ok {
+			continue
+		}
+		content, err := io.ReadAll(limits.reader(ctx, tr, header.Size))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, CompanionFile{name: header.Name, dest: dest, content: content})
+	}
+}
+
+// zipCompanions returns companion files of given executable binary in zip file. Each file is read within given limits.
+func zipCompanions(ctx context.Context, r io.ReaderAt, size int64, execBinary ExecBinary, limits ExtractLimits) ([]CompanionFile, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := []CompanionFile{}
+	for _, f := range zr.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		dest, ok := companionDest(f.Name, execBinary.installName)
+		if !ok {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(limits.reader(ctx, rc, int64(f.CompressedSize64)))
+		if err := errors.Join(err, rc.Close()); err != nil {
+			return nil, err
+		}
+		files = append(files, CompanionFile{name: f.Name, dest: dest, content: content})
+	}
+	return files, nil
+}
+
+// CompanionRecord is a record of companion file installed by this tool.
+type CompanionRecord struct {
+	// Path is an absolute path where companion file was installed.
+	Path string `json:"path"`
+
+	// Digest is a digest of installed companion file content in "sha256:HEX" format.
+	Digest string `json:"digest"`
+}
+
+// companionPath returns an absolute path where given companion file is installed under given share directory.
+func companionPath(shareDir string, f CompanionFile) (string, error) {
+	return filepath.Abs(filepath.Join(shareDir, filepath.FromSlash(f.dest)))
+}
+
+// installCompanions writes given companion files into given share directory and returns records of them.
+// Files are written atomically under lock of their directories, and existing files are overwritten.
+func installCompanions(shareDir string, files []CompanionFile) ([]CompanionRecord, error) {
+	records := []CompanionRecord{}
+	for _, f := range files {
+		p, err := companionPath(shareDir, f)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFileLocked(p, f.content, 0644); err != nil {
+			return nil, fmt.Errorf("failed to install %s: %w", f.name, err)
+		}
+		records = append(records, CompanionRecord{Path: p, Digest: digest(f.content)})
+	}
+	return records, nil
+}
+
+// writeFileLocked writes given data into a file atomically under lock of its directory.
+func writeFileLocked(path string, data []byte, perm os.FileMode) error {
+	unlock, err := lockDir(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer unlock() // nolint:errcheck
+	return writeFileAtomic(path, data, perm)
+}
+
+// removeCompanions removes given companion files, skipping ones which were modified after installation.
+// Paths of skipped files are returned so that caller can warn about them.
+func removeCompanions(records []CompanionRecord) ([]string, error) {
+	skipped := []string{}
+	errs := []error{}
+	for _, record := range records {
+		content, err := os.ReadFile(record.Path)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+		case err != nil:
+			errs = append(errs, err)
+		case digest(content) != record.Digest:
+			skipped = append(skipped, record.Path)
+		default:
+			errs = append(errs, os.Remove(record.Path))
+		}
+	}
+	return skipped, errors.Join(errs...)
+}
+
+// defaultShareDir returns a share directory where companion files are installed, based on directory where executable binary is installed.
+// If executable binary is installed into "<prefix>/bin", this returns "<prefix>/share" following FHS. Otherwise, this returns XDG data directory.
+func defaultShareDir(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err == nil && filepath.Base(abs) == "bin" {
+		return filepath.Join(filepath.Dir(abs), "share")
+	}
+	return xdgDataHome()
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanionDest(t *testing.T) {
+	tests := []struct {
+		name string
+		dest string
+		ok   bool
+	}{
+		{name: "gh_2.60.0_linux_amd64/share/man/man1/gh.1", dest: "man/man1/gh.1", ok: true},
+		{name: "gh_2.60.0_linux_amd64/share/man/man1/gh-pr-create.1", dest: "man/man1/gh-pr-create.1", ok: true},
+		{name: "ripgrep-14.1.0-x86_64-unknown-linux-musl/doc/rg.1.gz", dest: "man/man1/rg.1.gz", ok: true},
+		{name: "ripgrep-14.1.0-x86_64-unknown-linux-musl/complete/rg.bash", dest: "bash-completion/completions/rg", ok: true},
+		{name: "ripgrep-14.1.0-x86_64-unknown-linux-musl/complete/_rg", dest: "zsh/site-functions/_rg", ok: true},
+		{name: "ripgrep-14.1.0-x86_64-unknown-linux-musl/complete/rg.fish", dest: "fish/vendor_completions.d/rg.fish", ok: true},
+		{name: "tool/completions/bash/tool", dest: "bash-completion/completions/tool", ok: true},
+		{name: "tool/completions/tool.zsh", dest: "zsh/site-functions/_tool", ok: true},
+		{name: "linux-amd64/LICENSE", dest: "licenses/tool/LICENSE", ok: true},
+		{name: "COPYING.txt", dest: "licenses/tool/COPYING.txt", ok: true},
+		{name: "linux-amd64/helm", ok: false},
+		{name: "linux-amd64/README.md", ok: false},
+		{name: "tool-1.2.3", ok: false},
+		{name: "_internal", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest, ok := companionDest(tt.name, "tool")
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.dest, dest)
+		})
+	}
+}
+
+func TestInstallAndRemoveCompanions(t *testing.T) {
+	require := require.New(t)
+
+	var tarball bytes.Buffer
+	gw := gzip.NewWriter(&tarball)
+	tw := tar.NewWriter(gw)
+	for name, content := range map[string]string{
+		"tool/tool":               "\x7fELF",
+		"tool/man/tool.1":         ".TH TOOL 1",
+		"tool/completions/_tool":  "#compdef tool",
+		"tool/LICENSE":            "MIT",
+		"tool/docs/CHANGELOG.md":  "changes",
+		"tool/completions/README": "readme",
+	} {
+		require.NoError(tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(err)
+	}
+	require.NoError(tw.Close())
+	require.NoError(gw.Close())
+
+	files, err := AssetContent(tarball.Bytes()).companions(context.Background(), ExecBinary{name: "tool", installName: "tool"}, ExtractLimits{})
+	require.NoError(err)
+	require.Len(files, 3)
+
+	shareDir := t.TempDir()
+	records, err := installCompanions(shareDir, files)
+	require.NoError(err)
+	require.Len(records, 3)
+	require.FileExists(filepath.Join(shareDir, "man", "man1", "tool.1"))
+	require.FileExists(filepath.Join(shareDir, "zsh", "site-functions", "_tool"))
+	require.FileExists(filepath.Join(shareDir, "licenses", "tool", "LICENSE"))
+
+	modified := filepath.Join(shareDir, "licenses", "tool", "LICENSE")
+	require.NoError(os.WriteFile(modified, []byte("modified"), 0644))
+	skipped, err := removeCompanions(records)
+	require.NoError(err)
+	require.Equal([]string{modified}, skipped)
+	require.NoFileExists(filepath.Join(shareDir, "man", "man1", "tool.1"))
+	require.NoFileExists(filepath.Join(shareDir, "zsh", "site-functions", "_tool"))
+	require.FileExists(modified)
+}
+
+func TestAssetContentCompanionsLimits(t *testing.T) {
+	manPage := make([]byte, 4<<20)
+
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, err := zw.Create("tool/man/tool.1")
+	require.NoError(t, err)
+	_, err = w.Write(manPage)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	var tarball bytes.Buffer
+	tw := tar.NewWriter(&tarball)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "tool/man/tool.1", Mode: 0644, Size: int64(len(manPage)), Typeflag: tar.TypeReg}))
+	_, err = tw.Write(manPage)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	tests := []struct {
+		name    string
+		content []byte
+		limits  ExtractLimits
+		code    ErrorCode
+	}{
+		{
+			name:    "ZipWithinLimits",
+			content: zipped.Bytes(),
+			limits:  ExtractLimits{maxRatio: 10000},
+		},
+		{
+			name:    "ZipCompressionRatioExceeded",
+			content: zipped.Bytes(),
+			limits:  ExtractLimits{maxRatio: 10},
+			code:    ErrCompressionRatio,
+		},
+		{
+			name:    "ZipTooLarge",
+			content: zipped.Bytes(),
+			limits:  ExtractLimits{maxSize: 1 << 20, maxRatio: 10000},
+			code:    ErrExtractTooLarge,
+		},
+		{
+			name:    "TarballTooLarge",
+			content: tarball.Bytes(),
+			limits:  ExtractLimits{maxSize: 1 << 20},
+			code:    ErrExtractTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := AssetContent(tt.content).companions(context.Background(), ExecBinary{name: "tool", installName: "tool"}, tt.limits)
+			if tt.code == "" {
+				require.NoError(t, err)
+				require.Len(t, files, 1)
+				require.Equal(t, manPage, files[0].content)
+				return
+			}
+			require.Error(t, err)
+			require.Equal(t, tt.code, errorCode(err))
+		})
+	}
+}
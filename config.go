@@ -0,0 +1,231 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a configuration loaded from configuration file.
+type Config struct {
+	// Tools are configurations for each tool keyed by GitHub repository name in [HOST/]OWNER/REPO format.
+	Tools map[string]ToolConfig `yaml:"tools"`
+
+	// Hosts are configurations for each host keyed by host name such as "gitlab.example.com".
+	Hosts map[string]HostConfig `yaml:"hosts"`
+
+	// HTTP is a configuration of HTTP client used for every request.
+	HTTP HTTPConfig `yaml:"http"`
+
+	// client is an HTTP client built from HTTP by loadConfig. defaultHTTPClient is used if this is nil.
+	client *http.Client
+}
+
+// HostConfig is a configuration for a host which serves releases.
+type HostConfig struct {
+	// Type is a type of API which host serves. This is one of "github", "gitlab" and "gitea". Forgejo and Codeberg are "gitea".
+	// This is ignored for OCI registry, which is chosen by "oci://" prefix of repository name.
+	Type string `yaml:"type"`
+
+	// APIURL is a base URL of API such as "https://gitlab.example.com/api/v4" or "https://ghes.example.com/api/v3". This is derived from host by default.
+	// For OCI registry, this is a base URL of registry such as "http://localhost:5000".
+	APIURL string `yaml:"apiURL"`
+
+	// UploadURL is a base URL of upload API of GitHub Enterprise Server such as "https://ghes.example.com/api/uploads". This is derived from host or APIURL by default.
+	UploadURL string `yaml:"uploadURL"`
+
+	// TokenEnv is a name of environment variable which contains access token for API.
+	// For OCI registry, this is password and defaults to "OCI_PASSWORD".
+	TokenEnv string `yaml:"tokenEnv"`
+
+	// UsernameEnv is a name of environment variable which contains username for OCI registry. This defaults to "OCI_USERNAME".
+	UsernameEnv string `yaml:"usernameEnv"`
+
+	// App is a GitHub App whose installation token is used if no other access token is found.
+	App *GitHubAppConfig `yaml:"app"`
+
+	// flagToken is an access token given by --token flag, which takes precedence over any other source.
+	flagToken string
+
+	// client is an HTTP client to access host, which is filled in by [Config.host].
+	client *http.Client
+}
+
+// GitHubAppConfig is a configuration of GitHub App to authenticate as.
+type GitHubAppConfig struct {
+	// ID is an ID of GitHub App.
+	ID int64 `yaml:"id"`
+
+	// InstallationID is an ID of installation of GitHub App. This is looked up from repository by default.
+	InstallationID int64 `yaml:"installationID"`
+
+	// PrivateKeyPath is a path of PEM encoded private key of GitHub App.
+	PrivateKeyPath string `yaml:"privateKeyPath"`
+}
+
+// SourceConfig is a configuration of release source for a tool whose releases are published on server other than repository's host.
+type SourceConfig struct {
+	// Type is a type of release source. This must be "http".
+	Type string `yaml:"type"`
+
+	// Index is a URL of HTML or JSON index which lists versions, such as "https://releases.hashicorp.com/terraform/".
+	Index string `yaml:"index"`
+
+	// VersionPattern is a regular expression to find versions in index. First capturing group is used as version if it exists.
+	// Default matches semantic versions such as "1.2.3".
+	VersionPattern string `yaml:"versionPattern"`
+
+	// Latest is a URL of JSON endpoint which returns the latest version. This is preferred to Index to find the latest version.
+	Latest string `yaml:"latest"`
+
+	// LatestField is a dot-separated path of field which contains the latest version in response of Latest endpoint, such as "current_version".
+	LatestField string `yaml:"latestField"`
+
+	// TagPrefix is a prefix added to versions to make release tags, such as "v".
+	TagPrefix string `yaml:"tagPrefix"`
+
+	// Assets are templates of release asset download URL. These can refer to "Tag" and "SemVer".
+	Assets []string `yaml:"assets"`
+}
+
+// knownHosts are default configurations of well-known hosts which don't serve GitHub API.
+var knownHosts = map[string]HostConfig{
+	"gitlab.com": {
+		Type: "gitlab",
+	},
+	"codeberg.org": {
+		Type: "gitea",
+	},
+}
+
+// ToolConfig is a configuration for a tool installed from a GitHub repository.
+type ToolConfig struct {
+	// VerifyRun is a command to run freshly installed executable binary to verify it works, such as "terraform version".
+	VerifyRun string `yaml:"verifyRun"`
+
+	// VerifyVersion requires output of VerifyRun command to contain release tag or semantic version.
+	VerifyVersion bool `yaml:"verifyVersion"`
+
+	// Source is a configuration of release source other than repository's host, such as HTTP directory listing.
+	Source *SourceConfig `yaml:"source"`
+
+	// MediaType is a media type of layer to install from OCI artifact, such as "application/vnd.acme.tool.binary.v1".
+	// All layers for target platform are candidates if this is empty.
+	MediaType string `yaml:"mediaType"`
+
+	// VersionProbe is a command to run executable binary which is already installed to check its version, such as "terraform version".
+	VersionProbe string `yaml:"versionProbe"`
+}
+
+// loadConfig reads configuration file and returns [Config] object.
+// If configuration file doesn't exist, this returns empty [Config] object.
+func loadConfig(path string) (Config, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+	var config Config
+	if err := yaml.Unmarshal(b, &config); err != nil {
+		return Config{}, err
+	}
+	config.client, err = newHTTPClient(config.HTTP)
+	if err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// tool returns configuration for a tool installed from given GitHub repository.
+// If no configuration is found, this returns empty [ToolConfig] object.
+func (c Config) tool(repo string) ToolConfig {
+	r, err := parseRepository(repo)
+	if err != nil {
+		return ToolConfig{}
+	}
+	for key, tool := range c.Tools {
+		if k, err := parseRepository(key); err == nil && k == r {
+			return tool
+		}
+	}
+	return ToolConfig{}
+}
+
+// host returns configuration for given host with defaults filled in.
+func (c Config) host(name string) HostConfig {
+	host, ok := c.Hosts[name]
+	if !ok {
+		host = knownHosts[name]
+	}
+	if host.Type == "" {
+		host.Type = "github"
+	}
+	host.client = c.httpClient()
+	switch host.Type {
+	case "gitlab":
+		if host.APIURL == "" {
+			host.APIURL = fmt.Sprintf("https://%s/api/v4", name)
+		}
+		if host.TokenEnv == "" {
+			host.TokenEnv = "GITLAB_TOKEN"
+		}
+	case "gitea":
+		if host.APIURL == "" {
+			host.APIURL = fmt.Sprintf("https://%s/api/v1", name)
+		}
+		if host.TokenEnv == "" {
+			host.TokenEnv = "GITEA_TOKEN"
+		}
+	}
+	return host
+}
+
+// httpClient returns HTTP client configured by configuration file.
+func (c Config) httpClient() *http.Client {
+	if c.client == nil {
+		return defaultHTTPClient
+	}
+	return c.client
+}
+
+// setFlagToken sets access token given by --token flag for given host.
+func (c *Config) setFlagToken(name string, token string) {
+	if c.Hosts == nil {
+		c.Hosts = map[string]HostConfig{}
+	}
+	host := c.host(name)
+	host.flagToken = token
+	c.Hosts[name] = host
+}
+
+// httpClient returns HTTP client to access host.
+func (h HostConfig) httpClient() *http.Client {
+	if h.client == nil {
+		return defaultHTTPClient
+	}
+	return h.client
+}
+
+// token returns access token for API read from environment variable.
+func (h HostConfig) token() string {
+	if h.TokenEnv == "" {
+		return ""
+	}
+	return os.Getenv(h.TokenEnv)
+}
+
+// defaultConfigPath returns a default path of configuration file.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gh-release-install", "config.yaml")
+}
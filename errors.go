@@ -0,0 +1,135 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// ErrorCode is a stable identifier of kind of error, which is reported in machine-readable output instead of free-form error message.
+type ErrorCode string
+
+const (
+	// ErrNoMatchingAsset means no release asset matched patterns.
+	ErrNoMatchingAsset ErrorCode = "no_matching_asset"
+
+	// ErrUnsupportedArchive means release asset was neither executable binary nor supported archive or compression format.
+	ErrUnsupportedArchive ErrorCode = "unsupported_archive"
+
+	// ErrExecBinaryNotFound means executable binary was not found in archive.
+	ErrExecBinaryNotFound ErrorCode = "exec_binary_not_found"
+
+	// ErrUnsafeArchive means entry or link in archive pointed absolute path or path outside archive.
+	ErrUnsafeArchive ErrorCode = "unsafe_archive"
+
+	// ErrNestingTooDeep means archives or compressions in release asset were nested deeper than limit.
+	ErrNestingTooDeep ErrorCode = "nesting_too_deep"
+
+	// ErrExtractTooLarge means content decompressed from release asset exceeded size limit.
+	ErrExtractTooLarge ErrorCode = "extract_too_large"
+
+	// ErrCompressionRatio means content decompressed from release asset exceeded compression ratio limit, which suggests zip bomb.
+	ErrCompressionRatio ErrorCode = "compression_ratio_exceeded"
+
+	// ErrExtractTimeout means extracting executable binary from release asset took longer than limit.
+	ErrExtractTimeout ErrorCode = "extract_timeout"
+
+	// ErrNotExecBinary means extracted file was not executable binary.
+	ErrNotExecBinary ErrorCode = "not_exec_binary"
+
+	// ErrArchMismatch means executable binary was built for platform other than target platform.
+	ErrArchMismatch ErrorCode = "arch_mismatch"
+
+	// ErrChecksumMismatch means digest of downloaded content didn't match expected one.
+	ErrChecksumMismatch ErrorCode = "checksum_mismatch"
+
+	// ErrVerificationFailed means freshly installed executable binary failed verification command.
+	ErrVerificationFailed ErrorCode = "verification_failed"
+
+	// ErrNotFound means server responded that repository, release or asset was not found.
+	ErrNotFound ErrorCode = "not_found"
+
+	// ErrUnauthorized means server rejected credentials or lack of them.
+	ErrUnauthorized ErrorCode = "unauthorized"
+
+	// ErrRateLimited means request was rejected by rate limit.
+	ErrRateLimited ErrorCode = "rate_limited"
+
+	// ErrHTTP means server responded other unsuccessful status.
+	ErrHTTP ErrorCode = "http_error"
+
+	// ErrUnknown means error was not classified.
+	ErrUnknown ErrorCode = "unknown"
+)
+
+// CodedError is an error with [ErrorCode].
+type CodedError struct {
+	code ErrorCode
+	err  error
+}
+
+// withCode returns given error with given [ErrorCode]. This returns nil if err is nil.
+func withCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{
+		code: code,
+		err:  err,
+	}
+}
+
+// Error implements error.
+func (e *CodedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns underlying error.
+func (e *CodedError) Unwrap() error {
+	return e.err
+}
+
+// errorCode returns [ErrorCode] of given error. Unsuccessful HTTP responses are classified by their status code.
+func errorCode(err error) ErrorCode {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	statusCode, _ := httpStatus(err)
+	switch statusCode {
+	case 0:
+		return ErrUnknown
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrUnauthorized
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	default:
+		return ErrHTTP
+	}
+}
+
+// httpStatus returns status code and headers of unsuccessful HTTP response which caused given error.
+// Rate limit errors of GitHub API are regarded as 429 Too Many Requests. This returns zero if error was not caused by HTTP response.
+func httpStatus(err error) (int, http.Header) {
+	var (
+		errorResponse  *github.ErrorResponse
+		rateLimitError *github.RateLimitError
+		abuseRateLimit *github.AbuseRateLimitError
+		httpError      *HTTPError
+	)
+	switch {
+	case errors.As(err, &rateLimitError) && rateLimitError.Response != nil:
+		return http.StatusTooManyRequests, rateLimitError.Response.Header
+	case errors.As(err, &abuseRateLimit) && abuseRateLimit.Response != nil:
+		return http.StatusTooManyRequests, abuseRateLimit.Response.Header
+	case errors.As(err, &errorResponse) && errorResponse.Response != nil:
+		return errorResponse.Response.StatusCode, errorResponse.Response.Header
+	case errors.As(err, &httpError):
+		return httpError.statusCode, httpError.header
+	default:
+		return 0, nil
+	}
+}
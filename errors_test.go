@@ -0,0 +1,65 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code ErrorCode
+	}{
+		{name: "CodedError", err: withCode(ErrNoMatchingAsset, errors.New("no release asset matched")), code: ErrNoMatchingAsset},
+		{name: "WrappedCodedError", err: fmt.Errorf("failed: %w", withCode(ErrChecksumMismatch, errors.New("digest mismatch"))), code: ErrChecksumMismatch},
+		{name: "NotFound", err: &HTTPError{statusCode: http.StatusNotFound}, code: ErrNotFound},
+		{name: "Unauthorized", err: &HTTPError{statusCode: http.StatusUnauthorized}, code: ErrUnauthorized},
+		{name: "TooManyRequests", err: &HTTPError{statusCode: http.StatusTooManyRequests}, code: ErrRateLimited},
+		{name: "InternalServerError", err: &HTTPError{statusCode: http.StatusInternalServerError}, code: ErrHTTP},
+		{name: "GitHubRateLimit", err: &github.RateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}}, code: ErrRateLimited},
+		{name: "GitHubNotFound", err: &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}, code: ErrNotFound},
+		{name: "Unknown", err: errors.New("unknown"), code: ErrUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.code, errorCode(tt.err))
+		})
+	}
+}
+
+func TestExtractErrorCode(t *testing.T) {
+	var zipped bytes.Buffer
+	zw := zip.NewWriter(&zipped)
+	w, err := zw.Create("README.md")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("readme"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	tests := []struct {
+		name    string
+		content AssetContent
+		code    ErrorCode
+	}{
+		{name: "UnsupportedArchive", content: AssetContent("plain text"), code: ErrUnsupportedArchive},
+		{name: "ExecBinaryNotFound", content: AssetContent(zipped.Bytes()), code: ErrExecBinaryNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.content.extract(context.Background(), ExecBinary{name: "gh"}, ExtractLimits{})
+			require.Error(t, err)
+			require.Equal(t, tt.code, errorCode(err))
+		})
+	}
+}
@@ -2,7 +2,13 @@ package main
 
 // ExecBinary represents a executable binary in a GitHub release asset.
 type ExecBinary struct {
+	// name is a name of executable binary in a GitHub release asset.
+	// This is used to look up an executable binary in a GitHub release asset content.
 	name string
+
+	// installName is a name of executable binary to install as.
+	// This is same as name unless a template of executable binary name to install as is given.
+	installName string
 }
 
 // ExecBinaryContent represents an executable binary content in a GitHub release asset content.
@@ -10,10 +16,22 @@ type ExecBinaryContent []byte
 
 // ExecBinaryRepository is an interface about repository for [ExecBinary] and [ExecBinaryContent].
 type ExecBinaryRepository interface {
-	write(meta ExecBinary, content ExecBinaryContent) error
+	path(meta ExecBinary) string
+	read(meta ExecBinary) (ExecBinaryContent, error)
+	write(release Release, meta ExecBinary, content ExecBinaryContent) error
+	backup(meta ExecBinary) (func() error, error)
 }
 
-// newExecBinaryRepository returns a new [ExecBinaryRepository] object.
-func newExecBinaryRepository(dir string) ExecBinaryRepository {
-	return newFSExecBinaryRepository(dir)
+// newExecBinaryRepository returns a new [FSExecBinaryRepository] object or [VersionedExecBinaryRepository] object.
+// If store is empty, executable binary is written into given directory directly.
+// Otherwise, each version of executable binary is written into store and symbolic link to active one is placed in given directory.
+func newExecBinaryRepository(repo string, dir string, store string) (ExecBinaryRepository, error) {
+	if store == "" {
+		return newFSExecBinaryRepository(dir), nil
+	}
+	r, err := parseRepository(repo)
+	if err != nil {
+		return nil, err
+	}
+	return newVersionedExecBinaryRepository(r, dir, store), nil
 }
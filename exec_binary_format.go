@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExecBinaryFormat represents a result of inspecting header of an executable binary content.
+type ExecBinaryFormat struct {
+	// format is a name of executable file format. This is one of "ELF", "Mach-O" and "PE".
+	format string
+
+	// platforms are platforms which executable binary runs on.
+	// This has multiple platforms only if executable binary is a Mach-O universal binary.
+	platforms []Platform
+
+	// bits is bit width of executable binary.
+	bits int
+
+	// interpreter is a path of dynamic linker requested by executable binary. This is empty if executable binary is statically linked.
+	interpreter string
+
+	// libc is C standard library which executable binary is dynamically linked against. This is "glibc", "musl" or empty.
+	libc string
+}
+
+// inspect inspects header of [ExecBinaryContent] and returns [ExecBinaryFormat].
+// This returns an error if [ExecBinaryContent] is not ELF, Mach-O nor PE.
+func (c ExecBinaryContent) inspect() (ExecBinaryFormat, error) {
+	r := bytes.NewReader(c)
+	switch {
+	case bytes.HasPrefix(c, []byte(elf.ELFMAG)):
+		return inspectELF(r)
+	case bytes.HasPrefix(c, []byte("MZ")):
+		return inspectPE(r)
+	default:
+		if format, err := inspectMachO(r); err == nil {
+			return format, nil
+		}
+		return ExecBinaryFormat{}, withCode(ErrNotExecBinary, errors.New("executable binary content was neither ELF, Mach-O nor PE"))
+	}
+}
+
+// inspectELF inspects header of ELF executable binary content.
+func inspectELF(r io.ReaderAt) (ExecBinaryFormat, error) {
+	f, err := elf.NewFile(r)
+	if err != nil {
+		return ExecBinaryFormat{}, err
+	}
+
+	format := ExecBinaryFormat{
+		format: "ELF",
+		bits:   32,
+	}
+	if f.Class == elf.ELFCLASS64 {
+		format.bits = 64
+	}
+
+	noteOS := ""
+	for _, prog := range f.Progs {
+		switch prog.Type {
+		case elf.PT_INTERP:
+			b, err := io.ReadAll(prog.Open())
+			if err != nil {
+				return ExecBinaryFormat{}, err
+			}
+			format.interpreter = strings.TrimRight(string(b), "\x00")
+		case elf.PT_NOTE:
+			b, err := io.ReadAll(prog.Open())
+			if err != nil {
+				return ExecBinaryFormat{}, err
+			}
+			noteOS = cmp.Or(noteOS, elfNoteOS(b, f.ByteOrder))
+		}
+	}
+	format.platforms = []Platform{{
+		os:   elfOS(f.OSABI, noteOS, format.interpreter),
+		arch: elfArch(f.Machine, f.ByteOrder.String()),
+	}}
+
+	switch {
+	case strings.Contains(format.interpreter, "ld-musl"):
+		format.libc = "musl"
+	case strings.Contains(format.interpreter, "ld-linux"):
+		format.libc = "glibc"
+	}
+
+	return format, nil
+}
+
+// elfOS returns GOOS corresponding to given ELF OS ABI.
+// Most executable binaries including ones for Linux have ELFOSABI_NONE (System V) as OS ABI, so OS is told by ABI note such as "NetBSD" or "OpenBSD",
+// or by path of dynamic linker for such ABI. Only if neither tells OS, this assumes Linux, which is by far the most common target of ELF executable binaries released on GitHub.
+func elfOS(abi elf.OSABI, noteOS string, interpreter string) string {
+	switch abi {
+	case elf.ELFOSABI_FREEBSD:
+		return "freebsd"
+	case elf.ELFOSABI_NETBSD:
+		return "netbsd"
+	case elf.ELFOSABI_OPENBSD:
+		return "openbsd"
+	case elf.ELFOSABI_SOLARIS:
+		return "solaris"
+	case elf.ELFOSABI_LINUX:
+		return "linux"
+	}
+	switch {
+	case noteOS != "":
+		return noteOS
+	case strings.Contains(interpreter, "ld-elf.so"):
+		return "freebsd"
+	case strings.Contains(interpreter, "ld.elf_so"):
+		return "netbsd"
+	case strings.HasPrefix(interpreter, "/usr/libexec/ld.so"):
+		return "openbsd"
+	default:
+		return "linux"
+	}
+}
+
+// elfNoteOS returns GOOS told by ABI tag notes of BSDs in given content of PT_NOTE segment, or empty string if notes don't tell it.
+// GNU notes are ignored because they are also found in executable binaries for other OSes, such as build ID.
+func elfNoteOS(b []byte, order binary.ByteOrder) string {
+	for len(b) >= 12 {
+		namesz, descsz := order.Uint32(b[0:4]), order.Uint32(b[4:8])
+		nameEnd := 12 + uint64(namesz)
+		next := 12 + (uint64(namesz)+3)&^3 + (uint64(descsz)+3)&^3
+		if nameEnd > uint64(len(b)) || next > uint64(len(b)) {
+			return ""
+		}
+		switch strings.TrimRight(string(b[12:nameEnd]), "\x00") {
+		case "FreeBSD":
+			return "freebsd"
+		case "NetBSD":
+			return "netbsd"
+		case "OpenBSD":
+			return "openbsd"
+		}
+		b = b[next:]
+	}
+	return ""
+}
+
+// elfArch returns GOARCH corresponding to given ELF machine type.
+func elfArch(machine elf.Machine, byteOrder string) string {
+	switch machine {
+	case elf.EM_X86_64:
+		return "amd64"
+	case elf.EM_386:
+		return "386"
+	case elf.EM_AARCH64:
+		return "arm64"
+	case elf.EM_ARM:
+		return "arm"
+	case elf.EM_RISCV:
+		return "riscv64"
+	case elf.EM_S390:
+		return "s390x"
+	case elf.EM_PPC64:
+		if byteOrder == "LittleEndian" {
+			return "ppc64le"
+		}
+		return "ppc64"
+	default:
+		return strings.ToLower(strings.TrimPrefix(machine.String(), "EM_"))
+	}
+}
+
+// inspectMachO inspects header of Mach-O executable binary content including universal binary.
+func inspectMachO(r io.ReaderAt) (ExecBinaryFormat, error) {
+	if fat, err := macho.NewFatFile(r); err == nil {
+		format := ExecBinaryFormat{
+			format: "Mach-O",
+			bits:   64,
+		}
+		for _, arch := range fat.Arches {
+			format.platforms = append(format.platforms, Platform{
+				os:   "darwin",
+				arch: machoArch(arch.Cpu),
+			})
+		}
+		return format, nil
+	}
+
+	f, err := macho.NewFile(r)
+	if err != nil {
+		return ExecBinaryFormat{}, err
+	}
+	format := ExecBinaryFormat{
+		format: "Mach-O",
+		platforms: []Platform{{
+			os:   "darwin",
+			arch: machoArch(f.Cpu),
+		}},
+		bits: 32,
+	}
+	if f.Magic == macho.Magic64 {
+		format.bits = 64
+	}
+	return format, nil
+}
+
+// machoArch returns GOARCH corresponding to given Mach-O CPU type.
+func machoArch(cpu macho.Cpu) string {
+	switch cpu {
+	case macho.CpuAmd64:
+		return "amd64"
+	case macho.Cpu386:
+		return "386"
+	case macho.CpuArm64:
+		return "arm64"
+	case macho.CpuArm:
+		return "arm"
+	default:
+		return strings.ToLower(cpu.String())
+	}
+}
+
+// inspectPE inspects header of PE executable binary content.
+func inspectPE(r io.ReaderAt) (ExecBinaryFormat, error) {
+	f, err := pe.NewFile(r)
+	if err != nil {
+		return ExecBinaryFormat{}, err
+	}
+	format := ExecBinaryFormat{
+		format: "PE",
+		platforms: []Platform{{
+			os: "windows",
+		}},
+		bits: 32,
+	}
+	if _, ok := f.OptionalHeader.(*pe.OptionalHeader64); ok {
+		format.bits = 64
+	}
+	switch f.Machine {
+	case pe.IMAGE_FILE_MACHINE_AMD64:
+		format.platforms[0].arch = "amd64"
+	case pe.IMAGE_FILE_MACHINE_I386:
+		format.platforms[0].arch = "386"
+	case pe.IMAGE_FILE_MACHINE_ARM64:
+		format.platforms[0].arch = "arm64"
+	default:
+		format.platforms[0].arch = fmt.Sprintf("0x%x", f.Machine)
+	}
+	return format, nil
+}
+
+// check returns an error if executable binary doesn't run on given platform.
+func (f ExecBinaryFormat) check(platform Platform) error {
+	for _, p := range f.platforms {
+		if p == platform && f.bits == platform.bits() {
+			return nil
+		}
+	}
+	return withCode(ErrArchMismatch, fmt.Errorf("executable binary was built for %s but target platform is %s", f.platformsString(), platform))
+}
+
+// platformsString returns platforms which executable binary runs on as comma-separated string.
+func (f ExecBinaryFormat) platformsString() string {
+	ps := []string{}
+	for _, p := range f.platforms {
+		ps = append(ps, p.String())
+	}
+	return strings.Join(ps, ",")
+}
+
+// String returns human-readable description of executable binary format.
+// For example, "ELF 64-bit linux/amd64, dynamically linked (glibc, /lib64/ld-linux-x86-64.so.2)".
+func (f ExecBinaryFormat) String() string {
+	s := fmt.Sprintf("%s %d-bit %s", f.format, f.bits, f.platformsString())
+	switch {
+	case f.format != "ELF":
+		return s
+	case f.interpreter == "":
+		return s + ", statically linked"
+	case f.libc == "":
+		return fmt.Sprintf("%s, dynamically linked (%s)", s, f.interpreter)
+	default:
+		return fmt.Sprintf("%s, dynamically linked (%s, %s)", s, f.libc, f.interpreter)
+	}
+}
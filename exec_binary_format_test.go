@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// elfProg is a program header and its content in ELF fixture.
+type elfProg struct {
+	typ  elf.ProgType
+	data []byte
+}
+
+// elfFixture returns minimal 64-bit little endian ELF executable which has given OS ABI, machine and program headers.
+func elfFixture(t *testing.T, abi elf.OSABI, machine elf.Machine, progs ...elfProg) []byte {
+	t.Helper()
+	header := elf.Header64{
+		Type:      uint16(elf.ET_EXEC),
+		Machine:   uint16(machine),
+		Version:   uint32(elf.EV_CURRENT),
+		Phoff:     64,
+		Ehsize:    64,
+		Phentsize: 56,
+		Phnum:     uint16(len(progs)),
+		Shentsize: 64,
+	}
+	copy(header.Ident[:], elf.ELFMAG)
+	header.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	header.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	header.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+	header.Ident[elf.EI_OSABI] = byte(abi)
+
+	var b bytes.Buffer
+	require.NoError(t, binary.Write(&b, binary.LittleEndian, header))
+	off := uint64(64 + 56*len(progs))
+	for _, p := range progs {
+		prog := elf.Prog64{Type: uint32(p.typ), Off: off, Filesz: uint64(len(p.data)), Memsz: uint64(len(p.data)), Align: 1}
+		require.NoError(t, binary.Write(&b, binary.LittleEndian, prog))
+		off += uint64(len(p.data))
+	}
+	for _, p := range progs {
+		b.Write(p.data)
+	}
+	return b.Bytes()
+}
+
+// elfNote returns content of PT_NOTE segment which has single note of given name.
+func elfNote(name string) []byte {
+	var b bytes.Buffer
+	padded := append([]byte(name), make([]byte, 4-len(name)%4)...)
+	binary.Write(&b, binary.LittleEndian, []uint32{uint32(len(name) + 1), 4, 1}) // nolint:errcheck
+	b.Write(padded)
+	b.Write(make([]byte, 4))
+	return b.Bytes()
+}
+
+// machOFixture returns minimal 64-bit Mach-O executable for given CPU.
+func machOFixture(t *testing.T, cpu macho.Cpu) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	require.NoError(t, binary.Write(&b, binary.LittleEndian, macho.FileHeader{Magic: macho.Magic64, Cpu: cpu, Type: macho.TypeExec}))
+	require.NoError(t, binary.Write(&b, binary.LittleEndian, uint32(0))) // reserved field of 64-bit header.
+	return b.Bytes()
+}
+
+// fatFixture returns Mach-O universal binary which has executable for each of given CPUs.
+func fatFixture(t *testing.T, cpus ...macho.Cpu) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	require.NoError(t, binary.Write(&b, binary.BigEndian, []uint32{macho.MagicFat, uint32(len(cpus))}))
+	thin := [][]byte{}
+	off := uint32(8 + 20*len(cpus))
+	for _, cpu := range cpus {
+		f := machOFixture(t, cpu)
+		thin = append(thin, f)
+		require.NoError(t, binary.Write(&b, binary.BigEndian, macho.FatArchHeader{Cpu: cpu, Offset: off, Size: uint32(len(f))}))
+		off += uint32(len(f))
+	}
+	for _, f := range thin {
+		b.Write(f)
+	}
+	return b.Bytes()
+}
+
+// peFixture returns minimal 64-bit PE executable for given machine.
+func peFixture(t *testing.T, machine uint16) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	dos := make([]byte, 64)
+	copy(dos, "MZ")
+	binary.LittleEndian.PutUint32(dos[0x3c:], 64)
+	b.Write(dos)
+	b.WriteString("PE\x00\x00")
+	optional := pe.OptionalHeader64{Magic: 0x20b, NumberOfRvaAndSizes: 16}
+	require.NoError(t, binary.Write(&b, binary.LittleEndian, pe.FileHeader{Machine: machine, SizeOfOptionalHeader: uint16(binary.Size(optional))}))
+	require.NoError(t, binary.Write(&b, binary.LittleEndian, optional))
+	return b.Bytes()
+}
+
+func TestExecBinaryContentInspect(t *testing.T) {
+	require := require.New(t)
+
+	b, err := os.ReadFile(os.Args[0])
+	require.NoError(err)
+
+	format, err := ExecBinaryContent(b).inspect()
+	require.NoError(err)
+	require.Equal("ELF", format.format)
+	require.NoError(format.check(defaultPlatform))
+	require.Error(format.check(Platform{os: "linux", arch: "arm64"}))
+
+	_, err = ExecBinaryContent("<html><body>Not Found</body></html>").inspect()
+	require.Error(err)
+}
+
+func TestExecBinaryContentInspectFixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    string
+	}{
+		{
+			name:    "StaticELF",
+			content: elfFixture(t, elf.ELFOSABI_NONE, elf.EM_X86_64),
+			want:    "ELF 64-bit linux/amd64, statically linked",
+		},
+		{
+			name:    "GlibcELF",
+			content: elfFixture(t, elf.ELFOSABI_NONE, elf.EM_X86_64, elfProg{typ: elf.PT_INTERP, data: []byte("/lib64/ld-linux-x86-64.so.2\x00")}),
+			want:    "ELF 64-bit linux/amd64, dynamically linked (glibc, /lib64/ld-linux-x86-64.so.2)",
+		},
+		{
+			name:    "MuslELF",
+			content: elfFixture(t, elf.ELFOSABI_NONE, elf.EM_AARCH64, elfProg{typ: elf.PT_INTERP, data: []byte("/lib/ld-musl-aarch64.so.1\x00")}),
+			want:    "ELF 64-bit linux/arm64, dynamically linked (musl, /lib/ld-musl-aarch64.so.1)",
+		},
+		{
+			name:    "FreeBSDELFByOSABI",
+			content: elfFixture(t, elf.ELFOSABI_FREEBSD, elf.EM_X86_64),
+			want:    "ELF 64-bit freebsd/amd64, statically linked",
+		},
+		{
+			name:    "OpenBSDELFByNote",
+			content: elfFixture(t, elf.ELFOSABI_NONE, elf.EM_X86_64, elfProg{typ: elf.PT_NOTE, data: append(elfNote("Go"), elfNote("OpenBSD")...)}),
+			want:    "ELF 64-bit openbsd/amd64, statically linked",
+		},
+		{
+			name:    "NetBSDELFByInterpreter",
+			content: elfFixture(t, elf.ELFOSABI_NONE, elf.EM_X86_64, elfProg{typ: elf.PT_INTERP, data: []byte("/usr/libexec/ld.elf_so\x00")}),
+			want:    "ELF 64-bit netbsd/amd64, dynamically linked (/usr/libexec/ld.elf_so)",
+		},
+		{
+			name:    "Mach-O",
+			content: machOFixture(t, macho.CpuArm64),
+			want:    "Mach-O 64-bit darwin/arm64",
+		},
+		{
+			name:    "MachOUniversalBinary",
+			content: fatFixture(t, macho.CpuAmd64, macho.CpuArm64),
+			want:    "Mach-O 64-bit darwin/amd64,darwin/arm64",
+		},
+		{
+			name:    "PE",
+			content: peFixture(t, pe.IMAGE_FILE_MACHINE_AMD64),
+			want:    "PE 64-bit windows/amd64",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := ExecBinaryContent(tt.content).inspect()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, format.String())
+		})
+	}
+}
+
+func TestExecBinaryFormatCheck(t *testing.T) {
+	linuxAmd64 := Platform{os: "linux", arch: "amd64"}
+	darwinArm64 := Platform{os: "darwin", arch: "arm64"}
+
+	tests := []struct {
+		name     string
+		content  []byte
+		platform Platform
+		ok       bool
+	}{
+		{name: "SamePlatform", content: elfFixture(t, elf.ELFOSABI_NONE, elf.EM_X86_64), platform: linuxAmd64, ok: true},
+		{name: "OtherArch", content: elfFixture(t, elf.ELFOSABI_NONE, elf.EM_AARCH64), platform: linuxAmd64, ok: false},
+		{name: "OtherOS", content: machOFixture(t, macho.CpuAmd64), platform: linuxAmd64, ok: false},
+		{name: "UniversalBinary", content: fatFixture(t, macho.CpuAmd64, macho.CpuArm64), platform: darwinArm64, ok: true},
+		{name: "PEOnLinux", content: peFixture(t, pe.IMAGE_FILE_MACHINE_AMD64), platform: linuxAmd64, ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := ExecBinaryContent(tt.content).inspect()
+			require.NoError(t, err)
+			err = format.check(tt.platform)
+			if tt.ok {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Equal(t, ErrArchMismatch, errorCode(err))
+			}
+		})
+	}
+}
+
+func TestApplicationServiceInstallArchMismatch(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	asset := &fakeAssetRepository{
+		contents: map[string]AssetContent{
+			"https://github.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64": elfFixture(t, elf.ELFOSABI_NONE, elf.EM_AARCH64),
+		},
+	}
+	repo := Repository{host: "github.com", owner: "owner", name: "tool"}
+	app := newApplicationService(repo, asset, newFSExecBinaryRepository(dir), newStateRepository(filepath.Join(dir, ".state.json")))
+	ctx := context.Background()
+
+	a, execBinary, err := app.find(ctx, "v1.0.0", defaultPatterns, "")
+	require.NoError(err)
+
+	_, err = app.install(ctx, "v1.0.0", a, execBinary, InstallOptions{})
+	require.Error(err)
+	require.Equal(ErrArchMismatch, errorCode(err))
+	require.NoFileExists(filepath.Join(dir, "tool"))
+
+	result, err := app.install(ctx, "v1.0.0", a, execBinary, InstallOptions{allowArchMismatch: true})
+	require.NoError(err)
+	require.Error(result.archMismatch)
+	require.FileExists(filepath.Join(dir, "tool"))
+}
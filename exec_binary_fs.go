@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 )
@@ -17,8 +19,42 @@ func newFSExecBinaryRepository(dir string) *FSExecBinaryRepository {
 	}
 }
 
+// path returns a path where executable binary is installed.
+func (r *FSExecBinaryRepository) path(meta ExecBinary) string {
+	return filepath.Join(r.dir, meta.installName)
+}
+
+// read reads [ExecBinaryContent] installed in given repository.
+// This returns an error wrapping [fs.ErrNotExist] if executable binary is not installed.
+func (r *FSExecBinaryRepository) read(meta ExecBinary) (ExecBinaryContent, error) {
+	return os.ReadFile(r.path(meta))
+}
+
 // write writes [ExecBinaryContent] into a file in given repository.
-func (r *FSExecBinaryRepository) write(meta ExecBinary, content ExecBinaryContent) error {
-	path := filepath.Join(r.dir, meta.name)
-	return os.WriteFile(path, content, 0755)
+// File is replaced atomically, so that a running executable binary can be overwritten.
+func (r *FSExecBinaryRepository) write(_ Release, meta ExecBinary, content ExecBinaryContent) error {
+	unlock, err := lockDir(r.dir)
+	if err != nil {
+		return err
+	}
+	defer unlock() // nolint:errcheck
+	return writeFileAtomic(r.path(meta), content, 0755)
+}
+
+// backup saves executable binary currently installed and returns a function to restore it.
+// If no executable binary is installed, returned function removes one installed after this.
+func (r *FSExecBinaryRepository) backup(meta ExecBinary) (func() error, error) {
+	path := r.path(meta)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return func() error {
+			return os.Remove(path)
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		return writeFileAtomic(path, content, 0755)
+	}, nil
 }
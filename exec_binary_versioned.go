@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// VersionedExecBinaryRepository is a repository for [ExecBinary] and [ExecBinaryContent].
+// This keeps each version of executable binary in a store as "<store>/<host>/<owner>/<repo>/<tag>/<binary>" and places symbolic link to active one as "<dir>/<binary>".
+// Each of host, owner, repo and tag is made single path segment by [pathSegment], so that tag from remote can't point outside store.
+type VersionedExecBinaryRepository struct {
+	repo  Repository
+	dir   string // directory where symbolic links to active executable binaries are placed.
+	store string // root directory where each version of executable binary is kept.
+}
+
+// newVersionedExecBinaryRepository returns a new [VersionedExecBinaryRepository] object.
+func newVersionedExecBinaryRepository(repo Repository, dir string, store string) *VersionedExecBinaryRepository {
+	return &VersionedExecBinaryRepository{
+		repo:  repo,
+		dir:   dir,
+		store: store,
+	}
+}
+
+// findVersionedExecBinaryRepository returns a new [VersionedExecBinaryRepository] object for GitHub repository whose executable binary which has given name is kept in store.
+// If repo is not empty, this returns [VersionedExecBinaryRepository] object for it without looking up store.
+func findVersionedExecBinaryRepository(repo string, dir string, store string, name string) (*VersionedExecBinaryRepository, error) {
+	if repo != "" {
+		r, err := parseRepository(repo)
+		if err != nil {
+			return nil, err
+		}
+		return newVersionedExecBinaryRepository(r, dir, store), nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(store, "*", "*", "*", "*", name))
+	if err != nil {
+		return nil, err
+	}
+
+	repos := []Repository{}
+	for _, path := range paths {
+		rel, err := filepath.Rel(store, path)
+		if err != nil {
+			return nil, err
+		}
+		elems := strings.Split(rel, string(filepath.Separator))
+		r := Repository{
+			host:  elems[0],
+			owner: elems[1],
+			name:  elems[2],
+		}
+		if !slices.Contains(repos, r) {
+			repos = append(repos, r)
+		}
+	}
+
+	switch len(repos) {
+	case 0:
+		return nil, fmt.Errorf("no versions of %s were found in %s", name, store)
+	case 1:
+		return newVersionedExecBinaryRepository(repos[0], dir, store), nil
+	default:
+		return nil, fmt.Errorf("%s was found in multiple repositories; specify one of them by --repo", name)
+	}
+}
+
+// repoDir returns a directory where versions of executable binaries of repository are kept.
+func (r *VersionedExecBinaryRepository) repoDir() string {
+	return filepath.Join(r.store, pathSegment(r.repo.host), pathSegment(r.repo.owner), pathSegment(r.repo.name))
+}
+
+// path returns a path of symbolic link to active version of executable binary.
+func (r *VersionedExecBinaryRepository) path(meta ExecBinary) string {
+	return filepath.Join(r.dir, meta.installName)
+}
+
+// storePath returns a path where given version of executable binary is kept.
+func (r *VersionedExecBinaryRepository) storePath(release Release, meta ExecBinary) string {
+	return filepath.Join(r.repoDir(), pathSegment(release.tag), meta.installName)
+}
+
+// historyPath returns a path of file which records versions of executable binary activated in the past, oldest first.
+func (r *VersionedExecBinaryRepository) historyPath(meta ExecBinary) string {
+	return filepath.Join(r.repoDir(), ".history", meta.installName)
+}
+
+// read reads active version of [ExecBinaryContent].
+// This returns an error wrapping [fs.ErrNotExist] if no version of executable binary is active.
+func (r *VersionedExecBinaryRepository) read(meta ExecBinary) (ExecBinaryContent, error) {
+	return os.ReadFile(r.path(meta))
+}
+
+// lock acquires locks on store and on directory where symbolic links are placed, and returns a function to release them.
+// Locks are always acquired in this order so that processes writing into same store and directory don't deadlock.
+func (r *VersionedExecBinaryRepository) lock() (func() error, error) {
+	unlockStore, err := lockDir(r.store)
+	if err != nil {
+		return nil, err
+	}
+	store, storeErr := filepath.Abs(r.store)
+	dir, dirErr := filepath.Abs(r.dir)
+	if err := errors.Join(storeErr, dirErr); err != nil {
+		return nil, errors.Join(err, unlockStore())
+	}
+	if store == dir {
+		return unlockStore, nil
+	}
+	unlockDir, err := lockDir(r.dir)
+	if err != nil {
+		return nil, errors.Join(err, unlockStore())
+	}
+	return func() error {
+		return errors.Join(unlockDir(), unlockStore())
+	}, nil
+}
+
+// write writes [ExecBinaryContent] into store and activates it.
+func (r *VersionedExecBinaryRepository) write(release Release, meta ExecBinary, content ExecBinaryContent) error {
+	unlock, err := r.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock() // nolint:errcheck
+	if err := writeFileAtomic(r.storePath(release, meta), content, 0755); err != nil {
+		return err
+	}
+	return r.activate(release, meta)
+}
+
+// use activates given version of executable binary kept in store.
+func (r *VersionedExecBinaryRepository) use(release Release, meta ExecBinary) error {
+	unlock, err := r.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock() // nolint:errcheck
+	return r.activate(release, meta)
+}
+
+// activate activates given version of executable binary kept in store and appends it to history. Caller must hold lock.
+func (r *VersionedExecBinaryRepository) activate(release Release, meta ExecBinary) error {
+	if err := r.link(release, meta); err != nil {
+		return err
+	}
+	history, err := r.history(meta)
+	if err != nil {
+		return err
+	}
+	if len(history) > 0 && history[len(history)-1] == release.tag {
+		return nil
+	}
+	return r.writeHistory(meta, append(history, release.tag))
+}
+
+// rollback activates version of executable binary which was active before current one and returns it.
+func (r *VersionedExecBinaryRepository) rollback(meta ExecBinary) (Release, error) {
+	unlock, err := r.lock()
+	if err != nil {
+		return Release{}, err
+	}
+	defer unlock() // nolint:errcheck
+	history, err := r.history(meta)
+	if err != nil {
+		return Release{}, err
+	}
+	if len(history) < 2 {
+		return Release{}, fmt.Errorf("no previous versions of %s were found", meta.installName)
+	}
+	previous := Release{
+		tag: history[len(history)-2],
+	}
+	if err := r.link(previous, meta); err != nil {
+		return Release{}, err
+	}
+	return previous, r.writeHistory(meta, history[:len(history)-1])
+}
+
+// link replaces symbolic link in directory to point given version of executable binary kept in store.
+// Symbolic link is replaced atomically by renaming temporary one.
+func (r *VersionedExecBinaryRepository) link(release Release, meta ExecBinary) error {
+	target, err := filepath.Abs(r.storePath(release, meta))
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(target); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s %s is not installed in %s", meta.installName, release.tag, r.store)
+		}
+		return err
+	}
+
+	path := r.path(meta)
+	if info, err := os.Lstat(path); err == nil && info.Mode()&fs.ModeSymlink == 0 {
+		return fmt.Errorf("%s already exists and is not a symbolic link", path)
+	}
+	return symlinkAtomic(target, path)
+}
+
+// backup saves active version of executable binary and returns a function to activate it again.
+// If no version of executable binary is active, returned function removes symbolic link created after this.
+func (r *VersionedExecBinaryRepository) backup(meta ExecBinary) (func() error, error) {
+	path := r.path(meta)
+	target, err := os.Readlink(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	history, err := r.history(meta)
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		unlock, err := r.lock()
+		if err != nil {
+			return err
+		}
+		defer unlock() // nolint:errcheck
+		if target == "" {
+			return errors.Join(os.Remove(path), r.writeHistory(meta, history))
+		}
+		return errors.Join(symlinkAtomic(target, path), r.writeHistory(meta, history))
+	}, nil
+}
+
+// history returns versions of executable binary activated in the past, oldest first.
+func (r *VersionedExecBinaryRepository) history(meta ExecBinary) ([]string, error) {
+	f, err := os.Open(r.historyPath(meta))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint:errcheck
+
+	history := []string{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" {
+			history = append(history, line)
+		}
+	}
+	return history, s.Err()
+}
+
+// writeHistory writes versions of executable binary activated in the past, oldest first.
+func (r *VersionedExecBinaryRepository) writeHistory(meta ExecBinary, history []string) error {
+	return writeFileAtomic(r.historyPath(meta), []byte(strings.Join(history, "\n")+"\n"), 0644)
+}
+
+// storeOf returns store which symbolic link at given path points into if it was created by [VersionedExecBinaryRepository] for given GitHub repository.
+// Otherwise, this returns empty string.
+func storeOf(path string, repo Repository) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	tagDir := filepath.Dir(target)
+	repoDir := filepath.Dir(tagDir)
+	ownerDir := filepath.Dir(repoDir)
+	hostDir := filepath.Dir(ownerDir)
+	if filepath.Base(repoDir) != pathSegment(repo.name) || filepath.Base(ownerDir) != pathSegment(repo.owner) || filepath.Base(hostDir) != pathSegment(repo.host) {
+		return ""
+	}
+	return filepath.Dir(hostDir)
+}
+
+// defaultStore returns a default directory where each version of executable binary is kept.
+func defaultStore() string {
+	return filepath.Join(xdgDataHome(), "gh-release-install", "store")
+}
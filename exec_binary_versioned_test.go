@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"debug/elf"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionedExecBinaryRepository(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	store := t.TempDir()
+	repo := Repository{host: "github.com", owner: "hashicorp", name: "terraform"}
+	execBinary := ExecBinary{name: "terraform", installName: "terraform"}
+	path := filepath.Join(dir, "terraform")
+
+	r := newVersionedExecBinaryRepository(repo, dir, store)
+	require.NoError(r.write(Release{tag: "v1.8.0"}, execBinary, ExecBinaryContent("1.8.0")))
+	require.NoError(r.write(Release{tag: "v1.9.0"}, execBinary, ExecBinaryContent("1.9.0")))
+
+	content, err := os.ReadFile(path)
+	require.NoError(err)
+	require.Equal("1.9.0", string(content))
+
+	found, err := findVersionedExecBinaryRepository("", dir, store, "terraform")
+	require.NoError(err)
+	require.Equal(repo.host, found.repo.host)
+	require.Equal(repo.owner, found.repo.owner)
+	require.Equal(repo.name, found.repo.name)
+
+	release, err := found.rollback(execBinary)
+	require.NoError(err)
+	require.Equal("v1.8.0", release.tag)
+
+	content, err = os.ReadFile(path)
+	require.NoError(err)
+	require.Equal("1.8.0", string(content))
+
+	_, err = found.rollback(execBinary)
+	require.Error(err)
+
+	require.NoError(found.use(Release{tag: "v1.9.0"}, execBinary))
+	content, err = os.ReadFile(path)
+	require.NoError(err)
+	require.Equal("1.9.0", string(content))
+
+	require.Error(found.use(Release{tag: "v1.10.0"}, execBinary))
+}
+
+func TestVersionedExecBinaryRepositoryStorePath(t *testing.T) {
+	store := t.TempDir()
+	execBinary := ExecBinary{name: "tool", installName: "tool"}
+	github := newVersionedExecBinaryRepository(Repository{host: "github.com", owner: "owner", name: "tool"}, t.TempDir(), store)
+	gitlab := newVersionedExecBinaryRepository(Repository{host: "gitlab.com", owner: "owner", name: "tool"}, t.TempDir(), store)
+
+	require.NotEqual(t, github.storePath(Release{tag: "v1.0.0"}, execBinary), gitlab.storePath(Release{tag: "v1.0.0"}, execBinary))
+	for _, tag := range []string{"../../../escape", "..", "a/../../b", `..\escape`} {
+		rel, err := filepath.Rel(store, github.storePath(Release{tag: tag}, execBinary))
+		require.NoError(t, err)
+		require.True(t, filepath.IsLocal(rel), "store path of tag %q should be in store", tag)
+		require.Len(t, strings.Split(rel, string(filepath.Separator)), 5, "tag %q should be single path segment", tag)
+	}
+}
+
+func TestUseAndRollbackSaveInstallRecord(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	store := t.TempDir()
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	v1 := elfFixture(t, elf.ELFOSABI_NONE, elf.EM_X86_64)
+	v2 := append(elfFixture(t, elf.ELFOSABI_NONE, elf.EM_X86_64), 0)
+	asset := &fakeAssetRepository{}
+	repo := Repository{host: "github.com", owner: "owner", name: "tool"}
+	state := newStateRepository(statePath)
+	app := newApplicationService(repo, asset, newVersionedExecBinaryRepository(repo, dir, store), state)
+	ctx := context.Background()
+
+	for _, v := range []struct {
+		tag     string
+		content []byte
+	}{{tag: "v1.0.0", content: v1}, {tag: "v1.1.0", content: v2}} {
+		asset.contents = map[string]AssetContent{"https://github.com/owner/tool/releases/download/" + v.tag + "/tool_linux_amd64": v.content}
+		a, execBinary, err := app.find(ctx, v.tag, defaultPatterns, "")
+		require.NoError(err)
+		_, err = app.install(ctx, v.tag, a, execBinary, InstallOptions{})
+		require.NoError(err)
+	}
+
+	requireRecord := func(tag string, content []byte) {
+		t.Helper()
+		records, err := findInstallRecords(state, "tool", dir)
+		require.NoError(err)
+		require.Len(records, 1)
+		require.Equal(tag, records[0].Tag)
+		require.Equal(digest(content), records[0].Digest)
+		require.Equal("https://github.com/owner/tool/releases/download/"+tag+"/tool_linux_amd64", records[0].AssetURL)
+	}
+	requireRecord("v1.1.0", v2)
+
+	require.NoError(rollbackE("", "tool", dir, store, statePath))
+	requireRecord("v1.0.0", v1)
+
+	require.NoError(useE("", "tool", "v1.1.0", dir, store, statePath))
+	requireRecord("v1.1.0", v2)
+}
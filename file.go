@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// writeFileAtomic writes data into a file named by path atomically.
+// Data is written into temporary file in same directory, synced and renamed to path, so that a running executable binary can be replaced and an interrupted write never leaves a truncated file.
+// If file already exists, its mode and ownership are preserved. Otherwise, file is created with given permission and missing parent directories are created.
+// Writers racing on same file should hold lock on its directory by [lockDir].
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	uid, gid := -1, -1
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+		uid, gid = fileOwner(info)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // nolint:errcheck
+
+	if _, err := tmp.Write(data); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if uid != -1 && (uid != os.Getuid() || gid != os.Getgid()) {
+		if err := tmp.Chown(uid, gid); err != nil {
+			return errors.Join(err, tmp.Close())
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// symlinkAtomic creates or replaces path as a symbolic link to target atomically.
+// Symbolic link is created as temporary one in same directory and renamed to path. Missing parent directories are created.
+func symlinkAtomic(target string, path string) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, fmt.Sprintf(".%s.%d.tmp", filepath.Base(path), os.Getpid()))
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+	return syncDir(dir)
+}
+
+// pathSegment returns given string as single path segment, so that names from remote such as release tags can't point outside directory.
+// Separators are replaced with "_", and "." and ".." are prefixed with "_".
+func pathSegment(s string) string {
+	s = strings.NewReplacer("/", "_", "\\", "_").Replace(s)
+	if s == "" || s == "." || s == ".." {
+		return "_" + s
+	}
+	return s
+}
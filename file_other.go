@@ -0,0 +1,27 @@
+//go:build !unix
+
+package main
+
+import (
+	"io/fs"
+	"os"
+)
+
+// fileOwner returns -1 as user ID and group ID because ownership of file can't be preserved on this platform.
+func fileOwner(_ fs.FileInfo) (int, int) {
+	return -1, -1
+}
+
+// lockDir creates given directory if missing and returns a function which does nothing.
+// Advisory locks are not available on this platform, so concurrent writers are not serialized. Each file is still replaced atomically by rename.
+func lockDir(dir string) (func() error, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return func() error { return nil }, nil
+}
+
+// syncDir does nothing because directories can't be synced on this platform.
+func syncDir(_ string) error {
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	require := require.New(t)
+
+	path := filepath.Join(t.TempDir(), "bin", "tool")
+
+	require.NoError(writeFileAtomic(path, []byte("v1"), 0755))
+	info, err := os.Stat(path)
+	require.NoError(err)
+	require.Equal(os.FileMode(0755), info.Mode().Perm())
+
+	require.NoError(os.Chmod(path, 0700))
+	require.NoError(writeFileAtomic(path, []byte("v2"), 0755))
+	info, err = os.Stat(path)
+	require.NoError(err)
+	require.Equal(os.FileMode(0700), info.Mode().Perm())
+
+	content, err := os.ReadFile(path)
+	require.NoError(err)
+	require.Equal("v2", string(content))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(err)
+	require.Len(entries, 1, "temporary file was left")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// giteaRelease is a release returned by Gitea or Forgejo Releases API.
+type giteaRelease struct {
+	TagName    string              `json:"tag_name"`
+	Name       string              `json:"name"`
+	Body       string              `json:"body"`
+	Draft      bool                `json:"draft"`
+	Prerelease bool                `json:"prerelease"`
+	Assets     []giteaReleaseAsset `json:"assets"`
+}
+
+// giteaReleaseAsset is a release asset returned by Gitea or Forgejo Releases API.
+type giteaReleaseAsset struct {
+	ID                 int64  `json:"id"`
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// giteaClient is a client for Gitea or Forgejo Releases API.
+type giteaClient struct {
+	client  *http.Client
+	baseURL string
+	token   string
+	repo    Repository
+}
+
+// newGiteaClient returns a new [giteaClient] object for given Gitea repository.
+func newGiteaClient(repo Repository, host HostConfig) *giteaClient {
+	return &giteaClient{
+		client:  host.httpClient(),
+		baseURL: strings.TrimSuffix(host.APIURL, "/"),
+		token:   host.token(),
+		repo:    repo,
+	}
+}
+
+// header returns HTTP header to authenticate requests to Gitea API.
+func (c *giteaClient) header() http.Header {
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("Authorization", "token "+c.token)
+	}
+	return header
+}
+
+// repoURL returns URL of Gitea Repository API for repository.
+func (c *giteaClient) repoURL() string {
+	return fmt.Sprintf("%s/repos/%s/%s", c.baseURL, url.PathEscape(c.repo.owner), url.PathEscape(c.repo.name))
+}
+
+// release returns Gitea release which has given tag.
+func (c *giteaClient) release(ctx context.Context, tag string) (giteaRelease, error) {
+	var release giteaRelease
+	err := getJSON(ctx, c.client, fmt.Sprintf("%s/releases/tags/%s", c.repoURL(), url.PathEscape(tag)), c.header(), &release)
+	return release, err
+}
+
+// latestRelease returns the latest Gitea release which is neither draft nor prerelease.
+func (c *giteaClient) latestRelease(ctx context.Context) (giteaRelease, error) {
+	var release giteaRelease
+	err := getJSON(ctx, c.client, c.repoURL()+"/releases/latest", c.header(), &release)
+	return release, err
+}
+
+// releases returns Gitea releases in given page, newest first.
+func (c *giteaClient) releases(ctx context.Context, page int) ([]giteaRelease, error) {
+	releases := []giteaRelease{}
+	err := getJSON(ctx, c.client, fmt.Sprintf("%s/releases?limit=50&page=%d", c.repoURL(), page), c.header(), &releases)
+	return releases, err
+}
+
+// newRequest returns a new HTTP request to download given URL.
+// Access token is sent only if URL is served by same Gitea instance.
+func (c *giteaClient) newRequest(ctx context.Context, u *url.URL) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if base, err := url.Parse(c.baseURL); err == nil && sameOrigin(base, u) {
+		for k, vs := range c.header() {
+			req.Header[k] = vs
+		}
+	}
+	return req, nil
+}
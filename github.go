@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+	"github.com/google/go-github/v67/github"
+)
+
+// newGitHubClient returns a new GitHub API client for given GitHub repository's host and access token which it sends.
+// API of GitHub Enterprise Server and GHE.com is used if repository is hosted on them.
+func newGitHubClient(repo Repository, host HostConfig) (*github.Client, *GitHubToken, error) {
+	token, err := findGitHubToken(repo, host)
+	if err != nil {
+		return nil, nil, err
+	}
+	base := host.httpClient().Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client := github.NewClient(&http.Client{
+		Transport: &gitHubTokenTransport{
+			token: token,
+			base:  newRateLimitTransport(newCacheTransport(defaultCacheDir(), base)),
+		},
+		Timeout:       host.httpClient().Timeout,
+		CheckRedirect: checkRedirect,
+	})
+
+	apiURL, uploadURL := gitHubAPIURLs(repo.host, host)
+	if apiURL == "" {
+		return client, token, nil
+	}
+	client, err = client.WithEnterpriseURLs(apiURL, uploadURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("API URL of GitHub host %s was invalid: %w", repo.host, err)
+	}
+	return client, token, nil
+}
+
+// gitHubAPIURLs returns base URLs of REST API and upload API for given GitHub host.
+// URLs in configuration are preferred. Otherwise, they are derived from host name as follows.
+//
+//   - github.com: empty strings, which mean default URLs of go-github.
+//   - GHE.com such as "octocorp.ghe.com": "https://api.octocorp.ghe.com/" and "https://uploads.octocorp.ghe.com/".
+//   - GitHub Enterprise Server such as "ghes.example.com": "https://ghes.example.com/api/v3/" and "https://ghes.example.com/api/uploads/".
+func gitHubAPIURLs(hostname string, host HostConfig) (string, string) {
+	apiURL, uploadURL := host.APIURL, host.UploadURL
+	if apiURL == "" {
+		switch {
+		case auth.NormalizeHostname(hostname) == "github.com":
+			return "", ""
+		case auth.IsTenancy(hostname):
+			apiURL = fmt.Sprintf("https://api.%s/", hostname)
+			if uploadURL == "" {
+				uploadURL = fmt.Sprintf("https://uploads.%s/", hostname)
+			}
+		default:
+			apiURL = fmt.Sprintf("https://%s/api/v3/", hostname)
+			if uploadURL == "" {
+				uploadURL = fmt.Sprintf("https://%s/api/uploads/", hostname)
+			}
+		}
+	}
+	if uploadURL == "" {
+		uploadURL = strings.TrimSuffix(strings.TrimSuffix(apiURL, "/"), "/v3") + "/uploads/"
+	}
+	return apiURL, uploadURL
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubAPIURLs(t *testing.T) {
+	tests := []struct {
+		name      string
+		hostname  string
+		host      HostConfig
+		apiURL    string
+		uploadURL string
+	}{
+		{name: "GitHub", hostname: "github.com"},
+		{name: "GHE.com", hostname: "octocorp.ghe.com", apiURL: "https://api.octocorp.ghe.com/", uploadURL: "https://uploads.octocorp.ghe.com/"},
+		{name: "GHES", hostname: "ghes.example.com", apiURL: "https://ghes.example.com/api/v3/", uploadURL: "https://ghes.example.com/api/uploads/"},
+		{name: "ConfiguredAPIURL", hostname: "ghes.example.com", host: HostConfig{APIURL: "https://api.example.com/api/v3"}, apiURL: "https://api.example.com/api/v3", uploadURL: "https://api.example.com/api/uploads/"},
+		{name: "ConfiguredURLs", hostname: "ghes.example.com", host: HostConfig{APIURL: "https://api.example.com/", UploadURL: "https://uploads.example.com/"}, apiURL: "https://api.example.com/", uploadURL: "https://uploads.example.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiURL, uploadURL := gitHubAPIURLs(tt.hostname, tt.host)
+			require.Equal(t, tt.apiURL, apiURL)
+			require.Equal(t, tt.uploadURL, uploadURL)
+		})
+	}
+}
+
+// newFakeGHESServer starts a fake GitHub Enterprise Server which serves releases of "owner/tool" repository under "/api/v3".
+// Every API request requires token "secret".
+func newFakeGHESServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	release := map[string]any{"id": 1, "tag_name": "v1.0.0", "name": "v1.0.0", "body": "First release"}
+	mux.HandleFunc("GET /api/v3/repos/owner/tool/releases/latest", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(release) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /api/v3/repos/owner/tool/releases/tags/v1.0.0", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode(release) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /api/v3/repos/owner/tool/releases/1/assets", func(w http.ResponseWriter, _ *http.Request) {
+		json.NewEncoder(w).Encode([]map[string]any{ // nolint:errcheck
+			{"id": 10, "name": "tool_linux_amd64", "size": 6, "browser_download_url": "https://ghes.example.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64"},
+		})
+	})
+	mux.HandleFunc("GET /api/v3/repos/owner/tool/releases/assets/10", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == "application/octet-stream" {
+			fmt.Fprint(w, "binary") // nolint:errcheck
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 10, "name": "tool_linux_amd64", "size": 6}) // nolint:errcheck
+	})
+	return server
+}
+
+func TestGitHubEnterpriseServer(t *testing.T) {
+	require := require.New(t)
+
+	server := newFakeGHESServer(t)
+	t.Setenv("FAKE_GHES_TOKEN", "secret")
+	t.Setenv("GH_HOST", "ghes.example.com")
+	config := Config{
+		Hosts: map[string]HostConfig{
+			"ghes.example.com": {APIURL: server.URL + "/api/v3", TokenEnv: "FAKE_GHES_TOKEN"},
+		},
+	}
+	ctx := context.Background()
+
+	// Repository without host is on GH_HOST.
+	repo, err := parseRepository("owner/tool")
+	require.NoError(err)
+	require.Equal(Repository{host: "ghes.example.com", owner: "owner", name: "tool"}, repo)
+
+	r, err := newAssetRepository("owner/tool", config, io.Discard)
+	require.NoError(err)
+	assets, err := r.list(ctx, Release{tag: "v1.0.0"})
+	require.NoError(err)
+	require.Equal([]Asset{{id: 10, downloadURL: must(url.Parse("https://ghes.example.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64")), size: 6}}, assets)
+
+	content, err := r.download(ctx, assets[0])
+	require.NoError(err)
+	require.Equal(AssetContent("binary"), content)
+
+	releaseRepository, err := newReleaseRepository(repo, config)
+	require.NoError(err)
+	latest, err := releaseRepository.latest(ctx)
+	require.NoError(err)
+	require.Equal(Release{tag: "v1.0.0"}, latest)
+}
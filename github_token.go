@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// GitHubToken is an access token for GitHub API together with where it came from.
+// Tokens are looked up in the following order.
+//
+//  1. --token flag.
+//  2. Environment variable configured as tokenEnv of host.
+//  3. GH_TOKEN or GITHUB_TOKEN environment variable (GH_ENTERPRISE_TOKEN or GITHUB_ENTERPRISE_TOKEN for GitHub Enterprise Server).
+//  4. gh configuration, including token stored in system keyring by "gh auth login".
+//  5. Installation token of GitHub App configured as app of host.
+type GitHubToken struct {
+	// source describes where token came from. This is shown in error messages.
+	source string
+
+	// value is an access token. This is empty if token is issued by GitHub App or no token was found.
+	value string
+
+	// app issues installation token on demand if this is not nil.
+	app *gitHubApp
+}
+
+// findGitHubToken finds access token for given GitHub repository's host.
+// If no token is found, this returns [GitHubToken] which sends no credentials.
+func findGitHubToken(repo Repository, host HostConfig) (*GitHubToken, error) {
+	if host.flagToken != "" {
+		return &GitHubToken{source: "--token flag", value: host.flagToken}, nil
+	}
+	if token := host.token(); token != "" {
+		return &GitHubToken{source: fmt.Sprintf("environment variable %s", host.TokenEnv), value: token}, nil
+	}
+	if token, source := auth.TokenForHost(repo.host); token != "" {
+		switch source {
+		case "oauth_token":
+			source = "gh configuration"
+		case "gh":
+			source = `"gh auth token"`
+		default:
+			source = fmt.Sprintf("environment variable %s", source)
+		}
+		return &GitHubToken{source: source, value: token}, nil
+	}
+	if host.App != nil {
+		app, err := newGitHubApp(repo, host)
+		if err != nil {
+			return nil, err
+		}
+		return &GitHubToken{source: fmt.Sprintf("GitHub App %d", host.App.ID), app: app}, nil
+	}
+	return &GitHubToken{source: "no token"}, nil
+}
+
+// get returns access token. Installation token is issued if token comes from GitHub App.
+func (t *GitHubToken) get(ctx context.Context) (string, error) {
+	if t.app == nil {
+		return t.value, nil
+	}
+	token, err := t.app.installationToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to issue installation token of %s: %w", t.source, err)
+	}
+	return token, nil
+}
+
+// explain adds which token was used to given error if it may be caused by authentication, authorization or rate limiting.
+// GitHub API responds 404 to private repository which token can't access, so 404 is also regarded as such error.
+// Remaining rate limit is also added if response told it.
+func (t *GitHubToken) explain(err error) error {
+	if err == nil {
+		return nil
+	}
+	statusCode, header := httpStatus(err)
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusTooManyRequests:
+	default:
+		return err
+	}
+	message := fmt.Sprintf("token from %s was used", t.source)
+	if t.value == "" && t.app == nil {
+		message = "no token was used; set GH_TOKEN or pass --token to access private repository or to raise rate limit"
+	}
+	if rateLimit := rateLimitMessage(header); rateLimit != "" {
+		message = fmt.Sprintf("%s; %s", message, rateLimit)
+	}
+	return fmt.Errorf("%w (%s)", err, message)
+}
+
+// gitHubTokenTransport is a [http.RoundTripper] which sends access token to GitHub API.
+type gitHubTokenTransport struct {
+	token *GitHubToken
+	base  http.RoundTripper
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *gitHubTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.token.get(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// gitHubApp issues installation tokens of GitHub App.
+type gitHubApp struct {
+	client         *http.Client
+	apiURL         string
+	repo           Repository
+	id             int64
+	installationID int64
+	key            *rsa.PrivateKey
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// newGitHubApp returns a new [gitHubApp] object configured as app of given host.
+func newGitHubApp(repo Repository, host HostConfig) (*gitHubApp, error) {
+	b, err := os.ReadFile(host.App.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key of GitHub App %d: %w", host.App.ID, err)
+	}
+	key, err := parseRSAPrivateKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key of GitHub App %d: %w", host.App.ID, err)
+	}
+	apiURL, _ := gitHubAPIURLs(repo.host, host)
+	if apiURL == "" {
+		apiURL = "https://api.github.com/"
+	}
+	return &gitHubApp{
+		client:         host.httpClient(),
+		apiURL:         strings.TrimSuffix(apiURL, "/"),
+		repo:           repo,
+		id:             host.App.ID,
+		installationID: host.App.InstallationID,
+		key:            key,
+	}, nil
+}
+
+// parseRSAPrivateKey parses PEM encoded RSA private key in PKCS #1 or PKCS #8 format.
+func parseRSAPrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("PEM block was not found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key was not RSA key")
+	}
+	return rsaKey, nil
+}
+
+// jwt returns JSON Web Token to authenticate as GitHub App, which is valid for a few minutes.
+func (a *gitHubApp) jwt(now time.Time) (string, error) {
+	encode := func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return base64.RawURLEncoding.EncodeToString(b), nil
+	}
+	header, err := encode(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	// Issued time is set in the past to allow clock drift, as recommended by GitHub.
+	claims, err := encode(map[string]any{"iat": now.Add(-time.Minute).Unix(), "exp": now.Add(9 * time.Minute).Unix(), "iss": fmt.Sprint(a.id)})
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256([]byte(header + "." + claims))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return header + "." + claims + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// installationToken returns installation token of GitHub App. Token is cached until shortly before it expires.
+// If installation ID is not configured, installation for repository is looked up.
+func (a *gitHubApp) installationToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if a.token != "" && now.Add(time.Minute).Before(a.expiresAt) {
+		return a.token, nil
+	}
+
+	jwt, err := a.jwt(now)
+	if err != nil {
+		return "", err
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+jwt)
+
+	if a.installationID == 0 {
+		var installation struct {
+			ID int64 `json:"id"`
+		}
+		if err := getJSON(ctx, a.client, fmt.Sprintf("%s/repos/%s/%s/installation", a.apiURL, a.repo.owner, a.repo.name), header, &installation); err != nil {
+			return "", err
+		}
+		a.installationID = installation.ID
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/app/installations/%d/access_tokens", a.apiURL, a.installationID), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header = header
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if err := checkResponse(resp); err != nil {
+		return "", err
+	}
+	var body struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	a.token, a.expiresAt = body.Token, body.ExpiresAt
+	return a.token, nil
+}
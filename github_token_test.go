@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// isolateGitHubToken clears environment variables and gh configuration which provide GitHub access token.
+func isolateGitHubToken(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{"GH_TOKEN", "GITHUB_TOKEN", "GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	t.Setenv("GH_PATH", filepath.Join(t.TempDir(), "gh"))
+}
+
+func TestFindGitHubToken(t *testing.T) {
+	repo := Repository{host: "github.com", owner: "owner", name: "tool"}
+
+	tests := []struct {
+		name   string
+		env    map[string]string
+		host   HostConfig
+		source string
+		value  string
+	}{
+		{name: "Flag", env: map[string]string{"GH_TOKEN": "env", "FAKE_TOKEN": "configured"}, host: HostConfig{flagToken: "flag", TokenEnv: "FAKE_TOKEN"}, source: "--token flag", value: "flag"},
+		{name: "ConfiguredEnv", env: map[string]string{"GH_TOKEN": "env", "FAKE_TOKEN": "configured"}, host: HostConfig{TokenEnv: "FAKE_TOKEN"}, source: "environment variable FAKE_TOKEN", value: "configured"},
+		{name: "GH_TOKEN", env: map[string]string{"GH_TOKEN": "env", "GITHUB_TOKEN": "github"}, source: "environment variable GH_TOKEN", value: "env"},
+		{name: "GITHUB_TOKEN", env: map[string]string{"GITHUB_TOKEN": "github"}, source: "environment variable GITHUB_TOKEN", value: "github"},
+		{name: "None", source: "no token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateGitHubToken(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			token, err := findGitHubToken(repo, tt.host)
+			require.NoError(t, err)
+			require.Equal(t, tt.source, token.source)
+			require.Equal(t, tt.value, token.value)
+		})
+	}
+}
+
+func TestGitHubTokenExplain(t *testing.T) {
+	err := &HTTPError{method: http.MethodGet, url: must(url.Parse("https://api.github.com/repos/owner/tool")), status: "404 Not Found", statusCode: http.StatusNotFound}
+
+	require.EqualError(t, (&GitHubToken{source: "environment variable GH_TOKEN", value: "x"}).explain(err), "GET https://api.github.com/repos/owner/tool: 404 Not Found:  (token from environment variable GH_TOKEN was used)")
+	require.ErrorContains(t, (&GitHubToken{source: "no token"}).explain(err), "no token was used")
+	require.ErrorIs(t, (&GitHubToken{source: "no token"}).explain(err), err)
+
+	other := &HTTPError{statusCode: http.StatusInternalServerError}
+	require.Equal(t, error(other), (&GitHubToken{source: "no token"}).explain(other))
+}
+
+func TestGitHubAssetRepositoryPrivateDownload(t *testing.T) {
+	require := require.New(t)
+	isolateGitHubToken(t)
+
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, "binary") // nolint:errcheck
+	}))
+	t.Cleanup(storage.Close)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/owner/tool/releases/assets/10" || r.Header.Get("Accept") != "application/octet-stream" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.Redirect(w, r, storage.URL+"/tool_linux_amd64?signature=x", http.StatusFound)
+	}))
+	t.Cleanup(api.Close)
+
+	repo := Repository{host: "ghes.example.com", owner: "owner", name: "tool"}
+	asset := Asset{id: 10, downloadURL: must(url.Parse("https://ghes.example.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64"))}
+	ctx := context.Background()
+
+	r, err := newGitHubAssetRepository(repo, HostConfig{APIURL: api.URL + "/api/v3", flagToken: "secret"}, io.Discard)
+	require.NoError(err)
+	content, err := r.download(ctx, asset)
+	require.NoError(err)
+	require.Equal(AssetContent("binary"), content)
+
+	r, err = newGitHubAssetRepository(repo, HostConfig{APIURL: api.URL + "/api/v3", flagToken: "wrong"}, io.Discard)
+	require.NoError(err)
+	_, err = r.download(ctx, asset)
+	require.ErrorContains(err, "404 Not Found")
+	require.ErrorContains(err, "token from --token flag was used")
+}
+
+func TestGitHubAppToken(t *testing.T) {
+	require := require.New(t)
+	isolateGitHubToken(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	require.NoError(os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600))
+
+	// verifyJWT returns true if request is authenticated by JSON Web Token of GitHub App 1.
+	verifyJWT := func(r *http.Request) bool {
+		jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(jwt, ".")
+		if !ok || len(parts) != 3 {
+			return false
+		}
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		if err != nil {
+			return false
+		}
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+			return false
+		}
+		b, err := base64.RawURLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return false
+		}
+		var claims struct {
+			Issuer string `json:"iss"`
+		}
+		return json.Unmarshal(b, &claims) == nil && claims.Issuer == "1"
+	}
+	issued := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v3/repos/owner/tool/installation", func(w http.ResponseWriter, r *http.Request) {
+		if !verifyJWT(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"id": 5}) // nolint:errcheck
+	})
+	mux.HandleFunc("POST /api/v3/app/installations/5/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		if !verifyJWT(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		issued++
+		json.NewEncoder(w).Encode(map[string]any{"token": "secret", "expires_at": time.Now().Add(time.Hour)}) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /api/v3/repos/owner/tool/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"tag_name": "v1.0.0"}) // nolint:errcheck
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	repo := Repository{host: "ghes.example.com", owner: "owner", name: "tool"}
+	r, err := newGitHubReleaseRepository(repo, HostConfig{APIURL: server.URL + "/api/v3", App: &GitHubAppConfig{ID: 1, PrivateKeyPath: keyPath}})
+	require.NoError(err)
+	require.Equal("GitHub App 1", r.token.source)
+
+	for range 2 {
+		latest, err := r.latest(context.Background())
+		require.NoError(err)
+		require.Equal(Release{tag: "v1.0.0"}, latest)
+	}
+	require.Equal(1, issued)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// gitLabRelease is a release returned by GitLab Releases API.
+type gitLabRelease struct {
+	TagName         string `json:"tag_name"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	UpcomingRelease bool   `json:"upcoming_release"`
+	Assets          struct {
+		Links []gitLabReleaseLink `json:"links"`
+	} `json:"assets"`
+}
+
+// gitLabReleaseLink is a release asset link returned by GitLab Releases API.
+// This may point to generic package in GitLab package registry or to file hosted anywhere.
+type gitLabReleaseLink struct {
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	DirectAssetURL string `json:"direct_asset_url"`
+}
+
+// gitLabPackage is a package returned by GitLab Packages API.
+type gitLabPackage struct {
+	ID int64 `json:"id"`
+}
+
+// gitLabPackageFile is a file of package returned by GitLab Packages API.
+type gitLabPackageFile struct {
+	FileName string `json:"file_name"`
+	Size     int64  `json:"size"`
+}
+
+// gitLabGenericPackagePattern matches path of file in GitLab generic package and captures package name, version and file name.
+var gitLabGenericPackagePattern = regexp.MustCompile(`/packages/generic/([^/]+)/([^/]+)/([^/]+)$`)
+
+// gitLabClient is a client for GitLab Releases API.
+type gitLabClient struct {
+	client  *http.Client
+	baseURL string
+	token   string
+	repo    Repository
+}
+
+// newGitLabClient returns a new [gitLabClient] object for given GitLab project.
+func newGitLabClient(repo Repository, host HostConfig) *gitLabClient {
+	return &gitLabClient{
+		client:  host.httpClient(),
+		baseURL: strings.TrimSuffix(host.APIURL, "/"),
+		token:   host.token(),
+		repo:    repo,
+	}
+}
+
+// header returns HTTP header to authenticate requests to GitLab API.
+func (c *gitLabClient) header() http.Header {
+	header := http.Header{}
+	if c.token != "" {
+		header.Set("PRIVATE-TOKEN", c.token)
+	}
+	return header
+}
+
+// projectURL returns URL of GitLab Projects API for project.
+func (c *gitLabClient) projectURL() string {
+	return fmt.Sprintf("%s/projects/%s", c.baseURL, url.PathEscape(c.repo.owner+"/"+c.repo.name))
+}
+
+// release returns GitLab release which has given tag.
+func (c *gitLabClient) release(ctx context.Context, tag string) (gitLabRelease, error) {
+	var release gitLabRelease
+	err := getJSON(ctx, c.client, fmt.Sprintf("%s/releases/%s", c.projectURL(), url.PathEscape(tag)), c.header(), &release)
+	return release, err
+}
+
+// releases returns GitLab releases in given page, newest first.
+func (c *gitLabClient) releases(ctx context.Context, page int) ([]gitLabRelease, error) {
+	releases := []gitLabRelease{}
+	err := getJSON(ctx, c.client, fmt.Sprintf("%s/releases?order_by=released_at&sort=desc&per_page=100&page=%d", c.projectURL(), page), c.header(), &releases)
+	return releases, err
+}
+
+// packageFileSizes returns sizes of files in generic package of project which has given name and version, keyed by file name.
+func (c *gitLabClient) packageFileSizes(ctx context.Context, name string, version string) (map[string]int64, error) {
+	packages := []gitLabPackage{}
+	query := url.Values{"package_type": {"generic"}, "package_name": {name}, "package_version": {version}}
+	if err := getJSON(ctx, c.client, fmt.Sprintf("%s/packages?%s", c.projectURL(), query.Encode()), c.header(), &packages); err != nil {
+		return nil, err
+	}
+	sizes := map[string]int64{}
+	for _, p := range packages {
+		files := []gitLabPackageFile{}
+		if err := getJSON(ctx, c.client, fmt.Sprintf("%s/packages/%d/package_files", c.projectURL(), p.ID), c.header(), &files); err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			sizes[f.FileName] = f.Size
+		}
+	}
+	return sizes, nil
+}
+
+// genericPackageFile returns package name, version and file name if given URL points to file in generic package served by same GitLab instance.
+func (c *gitLabClient) genericPackageFile(u *url.URL) (string, string, string, bool) {
+	base, err := url.Parse(c.baseURL)
+	if err != nil || !sameOrigin(base, u) {
+		return "", "", "", false
+	}
+	m := gitLabGenericPackagePattern.FindStringSubmatch(u.Path)
+	if m == nil {
+		return "", "", "", false
+	}
+	return m[1], m[2], m[3], true
+}
+
+// newRequest returns a new HTTP request to download given URL.
+// Access token is sent only if URL is served by same GitLab instance, such as generic package.
+func (c *gitLabClient) newRequest(ctx context.Context, u *url.URL) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if base, err := url.Parse(c.baseURL); err == nil && sameOrigin(base, u) {
+		for k, vs := range c.header() {
+			req.Header[k] = vs
+		}
+	}
+	return req, nil
+}
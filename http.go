@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// getJSON sends GET request to given URL with given headers and decodes JSON response into v.
+func getJSON(ctx context.Context, client *http.Client, url string, header http.Header, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// download sends given request and returns response body, writing progress bar into progressBar while reading it.
+// size is an expected size of response body, which is used for progress bar if server doesn't tell it. Zero means unknown.
+func download(client *http.Client, req *http.Request, size int64, progressBar io.Writer) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	total := resp.ContentLength
+	if total < 0 {
+		total = size
+	}
+	pr := newProgressReader(resp.Body, path.Base(req.URL.Path), total, progressBar)
+	defer pr.Close() // nolint:errcheck
+
+	return io.ReadAll(pr)
+}
+
+// HTTPError is an error returned when HTTP response is not successful.
+type HTTPError struct {
+	method     string
+	url        *url.URL
+	status     string
+	statusCode int
+	header     http.Header
+	body       string // head of response body, which often contains error message from server.
+}
+
+// Error implements error.
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s %s: %s: %s", e.method, e.url, e.status, e.body)
+}
+
+// checkResponse returns [HTTPError] if given HTTP response is not successful.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return &HTTPError{
+		method:     resp.Request.Method,
+		url:        resp.Request.URL,
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		header:     resp.Header,
+		body:       strings.TrimSpace(string(b)),
+	}
+}
+
+// sameOrigin returns true if given URLs have same scheme and host, which means credentials for one can be sent to another.
+func sameOrigin(a *url.URL, b *url.URL) bool {
+	return a.Scheme == b.Scheme && strings.EqualFold(a.Host, b.Host)
+}
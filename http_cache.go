@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+)
+
+// cacheTransport is a [http.RoundTripper] which caches responses of GET requests on disk and revalidates them by conditional requests.
+// Response which has ETag or Last-Modified header is cached. Next request for same URL is sent with If-None-Match or If-Modified-Since header,
+// and cached response is returned if server responds 304 Not Modified. GitHub API doesn't count such requests against rate limit.
+type cacheTransport struct {
+	dir  string
+	base http.RoundTripper
+}
+
+// newCacheTransport returns a new [cacheTransport] object which caches responses into given directory.
+// If dir is empty, this returns base as is.
+func newCacheTransport(dir string, base http.RoundTripper) http.RoundTripper {
+	if dir == "" {
+		return base
+	}
+	return &cacheTransport{
+		dir:  dir,
+		base: base,
+	}
+}
+
+// defaultCacheDir returns a default directory where responses of API are cached.
+func defaultCacheDir() string {
+	dir := xdgCacheHome()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "gh-release-install", "http")
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	path := t.path(req)
+	cached := t.load(path, req)
+	if cached != nil {
+		req = req.Clone(req.Context())
+		if etag := cached.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := cached.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close() // nolint:errcheck
+		// Rate limit headers in fresh response are more accurate than cached ones.
+		for _, k := range []string{"X-Ratelimit-Limit", "X-Ratelimit-Remaining", "X-Ratelimit-Reset", "X-Ratelimit-Used", "X-Ratelimit-Resource"} {
+			if v := resp.Header.Get(k); v != "" {
+				cached.Header.Set(k, v)
+			}
+		}
+		return cached, nil
+	}
+	if cached != nil {
+		cached.Body.Close() // nolint:errcheck
+	}
+
+	if resp.StatusCode == http.StatusOK && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "") {
+		return t.store(path, resp)
+	}
+	return resp, nil
+}
+
+// path returns path of file which caches response to given request.
+// Authorization header is a part of cache key so that response for one token is never returned for another.
+func (t *cacheTransport) path(req *http.Request) string {
+	h := sha256.New()
+	for _, s := range []string{req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization")} {
+		h.Write([]byte(s)) // nolint:errcheck
+		h.Write([]byte{0}) // nolint:errcheck
+	}
+	return filepath.Join(t.dir, hex.EncodeToString(h.Sum(nil)))
+}
+
+// load returns cached response stored in given path. This returns nil if no valid cache exists.
+func (t *cacheTransport) load(path string, req *http.Request) *http.Response {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), req)
+	if err != nil {
+		return nil
+	}
+	return resp
+}
+
+// store writes given response into given path and returns response whose body can be read again.
+// Failure to write cache is ignored because cache is only optimization.
+func (t *cacheTransport) store(path string, resp *http.Response) (*http.Response, error) {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close() // nolint:errcheck
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	b, err := httputil.DumpResponse(resp, true)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return resp, nil
+	}
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		return resp, nil
+	}
+	writeFileAtomic(path, b, 0o600) // nolint:errcheck
+	return resp, nil
+}
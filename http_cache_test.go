@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheTransport(t *testing.T) {
+	require := require.New(t)
+
+	requests, notModified := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(60-requests))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"tag_name":"v1.0.0"}`) // nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	client := &http.Client{Transport: newCacheTransport(t.TempDir(), http.DefaultTransport)}
+	get := func(token string) (string, http.Header) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/repos/owner/tool/releases/latest", nil)
+		require.NoError(err)
+		req.Header.Set("Authorization", token)
+		resp, err := client.Do(req)
+		require.NoError(err)
+		defer resp.Body.Close() // nolint:errcheck
+		require.Equal(http.StatusOK, resp.StatusCode)
+		b, err := io.ReadAll(resp.Body)
+		require.NoError(err)
+		return string(b), resp.Header
+	}
+
+	body, _ := get("Bearer a")
+	require.Equal(`{"tag_name":"v1.0.0"}`, body)
+	require.Equal(0, notModified)
+
+	body, header := get("Bearer a")
+	require.Equal(`{"tag_name":"v1.0.0"}`, body)
+	require.Equal(1, notModified)
+	require.Equal("58", header.Get("X-RateLimit-Remaining"))
+
+	// Response cached for one token is not used for another.
+	body, _ = get("Bearer b")
+	require.Equal(`{"tag_name":"v1.0.0"}`, body)
+	require.Equal(1, notModified)
+	require.Equal(3, requests)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// HTTPConfig is a configuration of HTTP client used for every request to APIs and downloads.
+type HTTPConfig struct {
+	// Proxy is a URL of proxy such as "http://proxy.example.com:8080".
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables are used if this is empty.
+	Proxy string `yaml:"proxy"`
+
+	// NoProxy are hosts which are accessed without Proxy. Subdomains of these hosts are also accessed without Proxy.
+	NoProxy []string `yaml:"noProxy"`
+
+	// CACerts are paths of PEM files of CA certificates trusted in addition to system ones, such as CA of TLS-intercepting proxy.
+	CACerts []string `yaml:"caCerts"`
+
+	// ClientCert and ClientKey are paths of PEM files of client certificate and its private key for TLS client authentication.
+	ClientCert string `yaml:"clientCert"`
+	ClientKey  string `yaml:"clientKey"`
+
+	// ConnectTimeout is a timeout to establish TCP connection such as "10s". This defaults to 30 seconds.
+	ConnectTimeout time.Duration `yaml:"connectTimeout"`
+
+	// TLSHandshakeTimeout is a timeout of TLS handshake. This defaults to 10 seconds.
+	TLSHandshakeTimeout time.Duration `yaml:"tlsHandshakeTimeout"`
+
+	// ResponseHeaderTimeout is a timeout to wait for response headers after request is sent. No timeout by default.
+	ResponseHeaderTimeout time.Duration `yaml:"responseHeaderTimeout"`
+
+	// Timeout is a timeout of whole request including reading response body. No timeout by default.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Mirrors are rules to rewrite release asset download URL before download. The longest matching prefix is used.
+	Mirrors []MirrorConfig `yaml:"mirrors"`
+}
+
+// MirrorConfig is a rule to download release asset from mirror.
+type MirrorConfig struct {
+	// Prefix is a prefix of release asset download URL such as "https://dl.k8s.io/".
+	Prefix string `yaml:"prefix"`
+
+	// Mirror is a prefix which replaces Prefix, such as "https://mirror.example.com/dl.k8s.io/".
+	Mirror string `yaml:"mirror"`
+}
+
+// defaultHTTPClient is an HTTP client used if no HTTP client is configured.
+var defaultHTTPClient = &http.Client{
+	CheckRedirect: checkRedirect,
+}
+
+// newHTTPClient returns a new HTTP client configured by given configuration.
+// The client drops credentials when redirected to other origin, such as from API to storage serving release assets.
+func newHTTPClient(config HTTPConfig) (*http.Client, error) {
+	transport, err := newHTTPTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Transport:     transport,
+		Timeout:       config.Timeout,
+		CheckRedirect: checkRedirect,
+	}, nil
+}
+
+// newHTTPTransport returns a new [http.Transport] configured by given configuration.
+func newHTTPTransport(config HTTPConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if config.Proxy != "" {
+		proxy, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("proxy URL was invalid: %w", err)
+		}
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if bypassProxy(req.URL.Hostname(), config.NoProxy) {
+				return nil, nil
+			}
+			return proxy, nil
+		}
+	}
+
+	if len(config.CACerts) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, path := range config.CACerts {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(b) {
+				return nil, fmt.Errorf("no CA certificates were found in %s", path)
+			}
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
+	if config.ClientCert != "" || config.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if config.ConnectTimeout > 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   config.ConnectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	if config.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = config.TLSHandshakeTimeout
+	}
+	transport.ResponseHeaderTimeout = config.ResponseHeaderTimeout
+
+	return transport, nil
+}
+
+// bypassProxy returns true if given host is one of noProxy or their subdomains.
+func bypassProxy(host string, noProxy []string) bool {
+	for _, h := range noProxy {
+		h = strings.TrimPrefix(strings.ToLower(h), ".")
+		if h == "*" || strings.EqualFold(host, h) || strings.HasSuffix(strings.ToLower(host), "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRedirect follows up to 10 redirects, dropping credentials when redirected to other origin.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if !sameOrigin(req.URL, via[0].URL) {
+		req.Header.Del("Authorization")
+		req.Header.Del("PRIVATE-TOKEN")
+	}
+	return nil
+}
+
+// rewrite returns download URL rewritten by the longest matching mirror rule.
+// The second return value is false if no rule matches, in which case given URL is returned as is.
+func rewrite(u *url.URL, mirrors []MirrorConfig) (*url.URL, bool, error) {
+	s := u.String()
+	best := -1
+	for i, m := range mirrors {
+		if strings.HasPrefix(s, m.Prefix) && (best < 0 || len(m.Prefix) > len(mirrors[best].Prefix)) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return u, false, nil
+	}
+	rewritten, err := url.Parse(mirrors[best].Mirror + strings.TrimPrefix(s, mirrors[best].Prefix))
+	if err != nil {
+		return nil, false, fmt.Errorf("mirror URL was invalid: %w", err)
+	}
+	return rewritten, true, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewrite(t *testing.T) {
+	mirrors := []MirrorConfig{
+		{Prefix: "https://dl.k8s.io/", Mirror: "https://mirror.example.com/k8s/"},
+		{Prefix: "https://dl.k8s.io/release/", Mirror: "https://mirror.example.com/k8s-release/"},
+		{Prefix: "https://get.helm.sh/", Mirror: "https://mirror.example.com/helm/"},
+	}
+
+	tests := []struct {
+		name      string
+		url       string
+		rewrite   string
+		rewritten bool
+	}{
+		{name: "LongestPrefix", url: "https://dl.k8s.io/release/v1.30.0/bin/linux/amd64/kubectl", rewrite: "https://mirror.example.com/k8s-release/v1.30.0/bin/linux/amd64/kubectl", rewritten: true},
+		{name: "ShortPrefix", url: "https://dl.k8s.io/other", rewrite: "https://mirror.example.com/k8s/other", rewritten: true},
+		{name: "Helm", url: "https://get.helm.sh/helm-v3.15.0-linux-amd64.tar.gz", rewrite: "https://mirror.example.com/helm/helm-v3.15.0-linux-amd64.tar.gz", rewritten: true},
+		{name: "NoMatch", url: "https://github.com/owner/tool/releases/download/v1.0.0/tool", rewrite: "https://github.com/owner/tool/releases/download/v1.0.0/tool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, ok, err := rewrite(must(url.Parse(tt.url)), mirrors)
+			require.NoError(t, err)
+			require.Equal(t, tt.rewritten, ok)
+			require.Equal(t, tt.rewrite, u.String())
+		})
+	}
+}
+
+func TestBypassProxy(t *testing.T) {
+	noProxy := []string{"internal.example.com", ".corp"}
+	require.True(t, bypassProxy("internal.example.com", noProxy))
+	require.True(t, bypassProxy("api.internal.example.com", noProxy))
+	require.True(t, bypassProxy("git.corp", noProxy))
+	require.False(t, bypassProxy("example.com", noProxy))
+	require.False(t, bypassProxy("notinternal.example.com", noProxy))
+}
+
+func TestNewHTTPClientCACerts(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "ok") // nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := newHTTPClient(HTTPConfig{})
+	require.NoError(t, err)
+	_, err = client.Get(server.URL)
+	require.Error(t, err)
+
+	caCert := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+	client, err = newHTTPClient(HTTPConfig{CACerts: []string{caCert}})
+	require.NoError(t, err)
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close() // nolint:errcheck
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = newHTTPClient(HTTPConfig{CACerts: []string{filepath.Join(t.TempDir(), "missing.pem")}})
+	require.ErrorContains(t, err, "failed to read CA certificates")
+}
+
+func TestNewHTTPClientProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "proxied %s", r.URL) // nolint:errcheck
+	}))
+	t.Cleanup(proxy.Close)
+
+	client, err := newHTTPClient(HTTPConfig{Proxy: proxy.URL, NoProxy: []string{"internal.invalid"}})
+	require.NoError(t, err)
+	resp, err := client.Get("http://mirror.invalid/kubectl")
+	require.NoError(t, err)
+	defer resp.Body.Close() // nolint:errcheck
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "proxied http://mirror.invalid/kubectl", string(b))
+
+	_, err = client.Get("http://internal.invalid/kubectl")
+	require.Error(t, err)
+}
+
+func TestMirrorAssetRepository(t *testing.T) {
+	require := require.New(t)
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/k8s/release/v1.30.0/bin/linux/amd64/kubectl" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, "kubectl") // nolint:errcheck
+	}))
+	t.Cleanup(mirror.Close)
+
+	config := Config{
+		HTTP: HTTPConfig{
+			Mirrors: []MirrorConfig{{Prefix: "https://dl.k8s.io/", Mirror: mirror.URL + "/k8s/"}},
+		},
+	}
+	r, err := newAssetRepository("github.com/kubernetes/kubernetes", config, io.Discard)
+	require.NoError(err)
+	assets, err := r.list(context.Background(), Release{tag: "v1.30.0"})
+	require.NoError(err)
+	require.Len(assets, 1)
+	require.Equal("https://dl.k8s.io/release/v1.30.0/bin/linux/amd64/kubectl", assets[0].downloadURL.String())
+
+	content, err := r.download(context.Background(), assets[0])
+	require.NoError(err)
+	require.Equal(AssetContent("kubectl"), content)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRateLimitWait is the longest duration to wait for rate limit of GitHub API to be reset.
+// Request fails immediately if rate limit is reset later than this.
+const maxRateLimitWait = time.Minute
+
+// rateLimitTransport is a [http.RoundTripper] which waits and retries requests limited by primary or secondary rate limit of GitHub API.
+// If it must wait longer than maxWait, limited response is returned as is so that caller reports it.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	maxWait time.Duration
+	now     func() time.Time
+}
+
+// newRateLimitTransport returns a new [rateLimitTransport] object.
+func newRateLimitTransport(base http.RoundTripper) *rateLimitTransport {
+	return &rateLimitTransport{
+		base:    base,
+		maxWait: maxRateLimitWait,
+		now:     time.Now,
+	}
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for retried := false; ; retried = true {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		// Requests with body can't be sent again, and retried request is not retried again to avoid waiting forever.
+		if retried || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+			return resp, nil
+		}
+		wait, limited := t.wait(resp)
+		if !limited || wait > t.maxWait {
+			return resp, nil
+		}
+		resp.Body.Close() // nolint:errcheck
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// wait returns duration until rate limit is reset if given response was limited by rate limit.
+// Secondary rate limit is told by Retry-After header, and primary rate limit is told by X-RateLimit-Remaining and X-RateLimit-Reset headers.
+func (t *rateLimitTransport) wait(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		seconds, err := strconv.Atoi(retryAfter)
+		if err != nil {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return 0, false
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	// Wait one more second because reset time is truncated to seconds.
+	return max(time.Unix(reset, 0).Sub(t.now())+time.Second, 0), true
+}
+
+// rateLimitMessage returns human readable description of rate limit told by given response headers, such as "0/60 requests left until 15:04:05".
+// This returns empty string if headers don't tell rate limit.
+func rateLimitMessage(header http.Header) string {
+	limit, remaining, reset := header.Get("X-RateLimit-Limit"), header.Get("X-RateLimit-Remaining"), header.Get("X-RateLimit-Reset")
+	if limit == "" || remaining == "" {
+		return ""
+	}
+	message := fmt.Sprintf("%s/%s requests left", remaining, limit)
+	if seconds, err := strconv.ParseInt(reset, 10, 64); err == nil {
+		message = fmt.Sprintf("%s until %s", message, time.Unix(seconds, 0).Format(time.TimeOnly))
+	}
+	return message
+}
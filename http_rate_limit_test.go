@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitTransport(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name     string
+		header   map[string]string
+		now      time.Time
+		status   int
+		requests int
+	}{
+		{name: "PrimaryRateLimitReset", header: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": fmt.Sprint(reset.Unix())}, now: reset.Add(time.Second), status: http.StatusOK, requests: 2},
+		{name: "PrimaryRateLimitTooLong", header: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": fmt.Sprint(reset.Unix())}, now: reset.Add(-time.Hour), status: http.StatusForbidden, requests: 1},
+		{name: "SecondaryRateLimit", header: map[string]string{"Retry-After": "0"}, status: http.StatusOK, requests: 2},
+		{name: "SecondaryRateLimitTooLong", header: map[string]string{"Retry-After": "3600"}, status: http.StatusForbidden, requests: 1},
+		{name: "Forbidden", header: map[string]string{"X-RateLimit-Remaining": "10"}, status: http.StatusForbidden, requests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				requests++
+				if requests == 1 {
+					for k, v := range tt.header {
+						w.Header().Set(k, v)
+					}
+					w.WriteHeader(http.StatusForbidden)
+				}
+			}))
+			t.Cleanup(server.Close)
+
+			transport := newRateLimitTransport(http.DefaultTransport)
+			transport.now = func() time.Time { return tt.now }
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			require.NoError(t, err)
+			resp.Body.Close() // nolint:errcheck
+			require.Equal(t, tt.status, resp.StatusCode)
+			require.Equal(t, tt.requests, requests)
+		})
+	}
+}
+
+func TestRateLimitMessage(t *testing.T) {
+	reset := time.Date(2024, 1, 1, 15, 4, 5, 0, time.Local)
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "60")
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+	require.Equal(t, "0/60 requests left until 15:04:05", rateLimitMessage(header))
+	require.Equal(t, "", rateLimitMessage(http.Header{}))
+}
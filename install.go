@@ -0,0 +1,280 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// InstallTarget is an executable binary to install, given by flags or [Manifest].
+type InstallTarget struct {
+	repo        string
+	tag         string
+	constraint  bool // whether tag may be version constraint. This is true only for release selector given by "repo@selector" argument.
+	selector    WorkflowRunSelector
+	patterns    map[string]string
+	installName string
+	opts        InstallOptions
+}
+
+// splitRepositoryArgument splits positional argument such as "cli/cli@v2.60.0", "mikefarah/yq@latest" or "helm/helm@~3.16" into repository name and release selector.
+// Release selector is empty if argument has no "@". OCI reference is not split because its tag or digest is a part of it.
+func splitRepositoryArgument(arg string) (string, string) {
+	if strings.HasPrefix(arg, "oci://") {
+		return arg, ""
+	}
+	repo, selector, _ := strings.Cut(arg, "@")
+	return repo, selector
+}
+
+// newArgumentTarget returns [InstallTarget] of positional argument such as "cli/cli@v2.60.0".
+// Release selector in argument takes precedence over tag given by flag, and can't be used with workflow run selector.
+func newArgumentTarget(arg string, tag string, selector WorkflowRunSelector) (InstallTarget, error) {
+	repo, release := splitRepositoryArgument(arg)
+	target := InstallTarget{
+		repo:     repo,
+		selector: selector,
+	}
+	switch {
+	case release != "" && selector.selected():
+		return InstallTarget{}, fmt.Errorf(`release selector in argument %s can't be used with flags "run", "pr" and "branch"`, arg)
+	case release != "":
+		target.tag = release
+		target.constraint = true
+	case !selector.selected():
+		target.tag = cmp.Or(tag, defaultTag(repo))
+	}
+	return target, nil
+}
+
+// defaultTag returns tag of given OCI reference, or "latest" if repository is not OCI reference with tag.
+// This is used when repository is given without release selector by argument or [Manifest].
+func defaultTag(repo string) string {
+	if _, ref, err := parseOCIReference(repo); err == nil && ref != "" {
+		return ref
+	}
+	return "latest"
+}
+
+// Installation is an executable binary being installed.
+// Release asset and executable binary are resolved before user confirms installation, and are installed after that.
+type Installation struct {
+	target     InstallTarget
+	report     *InstallReport
+	repo       Repository
+	run        *WorkflowRun
+	tag        string
+	app        *ApplicationService
+	asset      Asset
+	execBinary ExecBinary
+
+	// from is a release of executable binary currently installed, after which release notes are shown.
+	from Release
+}
+
+// resolveInstallation resolves release tag, release asset and executable binary of given target, recording them into report.
+// Progress bars are written into progressBar. Unless dryRun is true or installation is forced, report status is set if executable binary is already up to date.
+func resolveInstallation(ctx context.Context, target InstallTarget, report *InstallReport, dir string, store string, statePath string, dryRun bool, config Config, progressBar io.Writer) (*Installation, error) {
+	r, err := parseRepository(target.repo)
+	if err != nil {
+		return nil, err
+	}
+	report.Repo = r.String()
+
+	tag := target.tag
+	var run *WorkflowRun
+	if target.selector.selected() {
+		if r.scheme != "" || config.host(r.host).Type != "github" {
+			return nil, fmt.Errorf("workflow run artifacts can be installed only from GitHub repository: %s", r)
+		}
+		client, token, err := newGitHubClient(r, config.host(r.host))
+		if err != nil {
+			return nil, err
+		}
+		found, err := findWorkflowRun(ctx, client, r, target.selector)
+		if err != nil {
+			return nil, token.explain(err)
+		}
+		run = &found
+		tag = run.tag()
+	}
+
+	if tag == "latest" || (target.constraint && isVersionConstraint(tag)) {
+		releaseRepository, err := newReleaseRepository(r, config)
+		if err != nil {
+			return nil, err
+		}
+		release, err := resolveRelease(ctx, releaseRepository, tag)
+		if err != nil {
+			return nil, err
+		}
+		tag = release.tag
+	}
+	report.Tag = tag
+
+	var assetRepository AssetRepository
+	if run != nil {
+		assetRepository, err = newGitHubArtifactAssetRepository(r, config.host(r.host), *run, progressBar)
+	} else {
+		assetRepository, err = newSourceAssetRepository(target.repo, config, progressBar)
+	}
+	if err != nil {
+		return nil, err
+	}
+	assetRepository = newMirrorAssetRepository(assetRepository, config, progressBar)
+	execBinaryRepository, err := newExecBinaryRepository(target.repo, dir, store)
+	if err != nil {
+		return nil, err
+	}
+	app := newApplicationService(r, assetRepository, execBinaryRepository, newStateRepository(statePath))
+
+	asset, execBinary, err := app.find(ctx, tag, target.patterns, target.installName)
+	if err != nil {
+		return nil, err
+	}
+	report.Asset = &AssetReport{
+		ID:   asset.id,
+		URL:  asset.downloadURL.String(),
+		Size: asset.size,
+	}
+	report.Name = execBinary.name
+	report.InstallName = execBinary.installName
+	if path, err := filepath.Abs(execBinaryRepository.path(execBinary)); err == nil {
+		report.Path = path
+	}
+
+	installation := &Installation{
+		target:     target,
+		report:     report,
+		repo:       r,
+		run:        run,
+		tag:        tag,
+		app:        app,
+		asset:      asset,
+		execBinary: execBinary,
+	}
+	if record, ok, err := app.installed(execBinary); err == nil && ok && record.Repo == r.String() {
+		installation.from = Release{tag: record.Tag}
+	}
+
+	if !dryRun && !target.opts.force {
+		upToDate, err := app.upToDate(ctx, tag, execBinary, target.opts)
+		if err != nil {
+			return nil, err
+		}
+		if upToDate {
+			if record, ok, err := app.installed(execBinary); err == nil && ok {
+				report.Digest = record.Digest
+			}
+			report.Status = statusUpToDate
+		}
+	}
+	return installation, nil
+}
+
+// prompt returns a message to confirm installation.
+func (i *Installation) prompt() string {
+	prompt := fmt.Sprintf("Do you want to install %s from %s ?", i.execBinary.name, i.asset.downloadURL.String())
+	if i.execBinary.installName != i.execBinary.name {
+		prompt = fmt.Sprintf("Do you want to install %s from %s as %s ?", i.execBinary.name, i.asset.downloadURL.String(), i.execBinary.installName)
+	}
+	if i.run != nil {
+		prompt = fmt.Sprintf("%s This is NOT a release build but an artifact of %s.", prompt, i.run)
+	}
+	return prompt
+}
+
+// plan records what would be written where into report without writing anything.
+// If extract is true, release asset is downloaded and executable binary is extracted from it.
+func (i *Installation) plan(ctx context.Context, extract bool) error {
+	plan, err := i.app.plan(ctx, i.tag, i.asset, i.execBinary, i.target.opts, extract)
+	if err != nil {
+		return err
+	}
+	report := i.report
+	report.Path = plan.path
+	report.TreeRoot = plan.treeRoot
+	report.Companions = plan.companions
+	if plan.replaces != nil {
+		report.Replaces = &ReplacedReport{
+			Version: plan.replaces.version,
+			Digest:  plan.replaces.digest,
+		}
+		if plan.replaces.record != nil {
+			report.Replaces.Repo = plan.replaces.record.Repo
+			report.Replaces.Tag = plan.replaces.record.Tag
+		}
+	}
+	if plan.extracted {
+		report.Digest = plan.digest
+		report.Size = plan.size
+		report.Format = plan.format.String()
+		if plan.archMismatch != nil {
+			report.Warnings = append(report.Warnings, plan.archMismatch.Error())
+		}
+	}
+	report.Status = statusPlanned
+	if plan.upToDate {
+		report.Status = statusUpToDate
+	}
+	return nil
+}
+
+// install installs executable binary and records the result into report.
+func (i *Installation) install(ctx context.Context) error {
+	opts := i.target.opts
+	if !maps.Equal(i.target.patterns, defaultPatterns) {
+		opts.patterns = i.target.patterns
+	}
+	opts.installName = i.target.installName
+	if i.run != nil {
+		opts.workflowRun = i.run.id
+	}
+	result, err := i.app.install(ctx, i.tag, i.asset, i.execBinary, opts)
+	if err != nil {
+		return err
+	}
+
+	report := i.report
+	report.Digest = result.digest
+	report.Size = result.size
+	report.Format = result.format.String()
+	if result.upToDate {
+		report.Status = statusUpToDate
+		return nil
+	}
+	report.Status = statusInstalled
+	if result.archMismatch != nil {
+		report.Warnings = append(report.Warnings, result.archMismatch.Error())
+	}
+	report.Verified = result.verified
+	report.verifyRun = opts.verifyRun
+	if result.record.Tree != nil {
+		report.TreeRoot = result.record.Tree.Root
+	}
+	if opts.shareDir != "" {
+		for _, companion := range result.record.Companions {
+			report.Companions = append(report.Companions, companion.Path)
+		}
+	}
+	return nil
+}
+
+// forEach calls f with each index in [0, n) concurrently, running at most jobs calls at once.
+func forEach(n int, jobs int, f func(i int)) {
+	sem := make(chan struct{}, max(jobs, 1))
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			f(i)
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForEach(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		jobs int
+	}{
+		{name: "Sequential", n: 10, jobs: 1},
+		{name: "Concurrent", n: 10, jobs: 3},
+		{name: "MoreJobsThanTasks", n: 2, jobs: 8},
+		{name: "Empty", n: 0, jobs: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, peak atomic.Int64
+			done := make([]bool, tt.n)
+			forEach(tt.n, tt.jobs, func(i int) {
+				current := running.Add(1)
+				for {
+					p := peak.Load()
+					if current <= p || peak.CompareAndSwap(p, current) {
+						break
+					}
+				}
+				done[i] = true
+				running.Add(-1)
+			})
+			require.LessOrEqual(t, peak.Load(), int64(tt.jobs))
+			for _, d := range done {
+				require.True(t, d)
+			}
+		})
+	}
+}
+
+func TestApplicationServiceInstallConcurrently(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	app := newFakeApplicationService(t, dir)
+	ctx := context.Background()
+
+	n := 8
+	errs := make([]error, n)
+	forEach(n, n, func(i int) {
+		asset, execBinary, err := app.find(ctx, "v1.0.0", defaultPatterns, fmt.Sprintf("tool-%d", i))
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		_, errs[i] = app.install(ctx, "v1.0.0", asset, execBinary, InstallOptions{})
+	})
+	for _, err := range errs {
+		require.NoError(err)
+	}
+
+	records, err := app.state.list()
+	require.NoError(err)
+	require.Len(records, n, "records saved concurrently should not be lost")
+}
+
+func TestSplitRepositoryArgument(t *testing.T) {
+	tests := []struct {
+		arg      string
+		repo     string
+		selector string
+	}{
+		{arg: "cli/cli@v2.60.0", repo: "cli/cli", selector: "v2.60.0"},
+		{arg: "mikefarah/yq@latest", repo: "mikefarah/yq", selector: "latest"},
+		{arg: "helm/helm@~3.16", repo: "helm/helm", selector: "~3.16"},
+		{arg: "gitlab.com/gitlab-org/cli", repo: "gitlab.com/gitlab-org/cli", selector: ""},
+		{arg: "oci://ghcr.io/owner/tool:v1.0.0", repo: "oci://ghcr.io/owner/tool:v1.0.0", selector: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			repo, selector := splitRepositoryArgument(tt.arg)
+			require.Equal(t, tt.repo, repo)
+			require.Equal(t, tt.selector, selector)
+		})
+	}
+}
+
+func TestNewArgumentTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      string
+		tag      string
+		selector WorkflowRunSelector
+		want     InstallTarget
+		wantErr  bool
+	}{
+		{
+			name: "ReleaseSelector",
+			arg:  "helm/helm@~3.16",
+			tag:  "v3.15.0",
+			want: InstallTarget{repo: "helm/helm", tag: "~3.16", constraint: true},
+		},
+		{
+			name: "TagFlag",
+			arg:  "helm/helm",
+			tag:  "~3.16",
+			want: InstallTarget{repo: "helm/helm", tag: "~3.16"},
+		},
+		{
+			name: "Latest",
+			arg:  "helm/helm",
+			want: InstallTarget{repo: "helm/helm", tag: "latest"},
+		},
+		{
+			name:     "WorkflowRun",
+			arg:      "cli/cli",
+			selector: WorkflowRunSelector{runID: 123},
+			want:     InstallTarget{repo: "cli/cli", selector: WorkflowRunSelector{runID: 123}},
+		},
+		{
+			name:     "ReleaseSelectorWithWorkflowRun",
+			arg:      "cli/cli@v2.60.0",
+			selector: WorkflowRunSelector{runID: 123},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := newArgumentTarget(tt.arg, tt.tag, tt.selector)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, target)
+		})
+	}
+}
@@ -1,6 +1,12 @@
 package main
 
 var (
+	// defaultPlatform is a platform which executable binary is installed for.
+	defaultPlatform = Platform{
+		os:   "linux",
+		arch: "amd64",
+	}
+
 	// defaultPatterns are recommended patterns for linux/amd64.
 	defaultPatterns = map[string]string{
 		// These are recommended patterns for general repository.
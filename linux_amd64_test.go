@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -27,7 +28,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/aquasecurity/trivy/releases/download/v0.69.3/trivy_0.69.3_Linux-64bit.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "trivy",
+				name:        "trivy",
+				installName: "trivy",
 			},
 			test: exec.Command("./trivy", "version"),
 		},
@@ -39,7 +41,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/argoproj/argo-cd/releases/download/v2.9.18/argocd-linux-amd64")),
 			},
 			execBinary: ExecBinary{
-				name: "argocd",
+				name:        "argocd",
+				installName: "argocd",
 			},
 			test: exec.Command("./argocd", "version", "--client"),
 		},
@@ -51,7 +54,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/argoproj/argo-rollouts/releases/download/v1.7.1/kubectl-argo-rollouts-linux-amd64")),
 			},
 			execBinary: ExecBinary{
-				name: "kubectl-argo-rollouts",
+				name:        "kubectl-argo-rollouts",
+				installName: "kubectl-argo-rollouts",
 			},
 			test: exec.Command("./kubectl-argo-rollouts", "version"),
 		},
@@ -63,7 +67,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/astral-sh/uv/releases/download/0.8.0/uv-x86_64-unknown-linux-gnu.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "uv",
+				name:        "uv",
+				installName: "uv",
 			},
 			test: exec.Command("./uv", "--version"),
 		},
@@ -75,7 +80,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/argoproj/argo-workflows/releases/download/v3.5.8/argo-linux-amd64.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "argo",
+				name:        "argo",
+				installName: "argo",
 			},
 			test: exec.Command("./argo", "version"),
 		},
@@ -87,7 +93,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/buildpacks/pack/releases/download/v0.34.2/pack-v0.34.2-linux.tgz")),
 			},
 			execBinary: ExecBinary{
-				name: "pack",
+				name:        "pack",
+				installName: "pack",
 			},
 			test: exec.Command("./pack", "version"),
 		},
@@ -99,7 +106,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/cli/cli/releases/download/v2.52.0/gh_2.52.0_linux_amd64.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "gh",
+				name:        "gh",
+				installName: "gh",
 			},
 			test: exec.Command("./gh", "version"),
 		},
@@ -111,7 +119,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/getsops/sops/releases/download/v3.9.0/sops-v3.9.0.linux.amd64")),
 			},
 			execBinary: ExecBinary{
-				name: "sops",
+				name:        "sops",
+				installName: "sops",
 			},
 			test: exec.Command("./sops", "--version"),
 		},
@@ -123,7 +132,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/github/copilot-cli/releases/download/v1.0.2/copilot-linux-x64.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "copilot",
+				name:        "copilot",
+				installName: "copilot",
 			},
 			test: exec.Command("./copilot", "--version"),
 		},
@@ -135,7 +145,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/goodwithtech/dockle/releases/download/v0.4.14/dockle_0.4.14_Linux-64bit.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "dockle",
+				name:        "dockle",
+				installName: "dockle",
 			},
 			test: exec.Command("./dockle", "--version"),
 		},
@@ -147,7 +158,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://cdn.teleport.dev/teleport-v16.4.6-linux-amd64-bin.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "tsh",
+				name:        "tsh",
+				installName: "tsh",
 			},
 			test: exec.Command("./tsh", "version"),
 		},
@@ -159,7 +171,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://releases.hashicorp.com/terraform/1.9.0/terraform_1.9.0_linux_amd64.zip")),
 			},
 			execBinary: ExecBinary{
-				name: "terraform",
+				name:        "terraform",
+				installName: "terraform",
 			},
 			test: exec.Command("./terraform", "version"),
 		},
@@ -171,7 +184,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://get.helm.sh/helm-v3.16.2-linux-amd64.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "helm",
+				name:        "helm",
+				installName: "helm",
 			},
 			test: exec.Command("./helm", "version"),
 		},
@@ -183,7 +197,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/istio/istio/releases/download/1.22.2/istioctl-1.22.2-linux-amd64.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "istioctl",
+				name:        "istioctl",
+				installName: "istioctl",
 			},
 			test: exec.Command("./istioctl", "version"),
 		},
@@ -195,7 +210,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/koalaman/shellcheck/releases/download/v0.10.0/shellcheck-v0.10.0.linux.x86_64.tar.xz")),
 			},
 			execBinary: ExecBinary{
-				name: "shellcheck",
+				name:        "shellcheck",
+				installName: "shellcheck",
 			},
 			test: exec.Command("./shellcheck", "--version"),
 		},
@@ -207,7 +223,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://dl.k8s.io/release/v1.31.0/bin/linux/amd64/kubectl")),
 			},
 			execBinary: ExecBinary{
-				name: "kubectl",
+				name:        "kubectl",
+				installName: "kubectl",
 			},
 			test: exec.Command("./kubectl", "version", "--client"),
 		},
@@ -219,7 +236,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/mikefarah/yq/releases/download/v4.44.2/yq_linux_amd64")),
 			},
 			execBinary: ExecBinary{
-				name: "yq",
+				name:        "yq",
+				installName: "yq",
 			},
 			test: exec.Command("./yq", "version"),
 		},
@@ -231,7 +249,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/open-policy-agent/conftest/releases/download/v0.53.0/conftest_0.53.0_Linux_x86_64.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "conftest",
+				name:        "conftest",
+				installName: "conftest",
 			},
 			test: exec.Command("./conftest", "--version"),
 		},
@@ -243,7 +262,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/open-policy-agent/gatekeeper/releases/download/v3.16.3/gator-v3.16.3-linux-amd64.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "gator",
+				name:        "gator",
+				installName: "gator",
 			},
 			test: exec.Command("./gator", "version"),
 		},
@@ -255,7 +275,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/open-policy-agent/opa/releases/download/v0.66.0/opa_linux_amd64")),
 			},
 			execBinary: ExecBinary{
-				name: "opa",
+				name:        "opa",
+				installName: "opa",
 			},
 			test: exec.Command("./opa", "version"),
 		},
@@ -267,7 +288,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/protocolbuffers/protobuf/releases/download/v27.2/protoc-27.2-linux-x86_64.zip")),
 			},
 			execBinary: ExecBinary{
-				name: "protoc",
+				name:        "protoc",
+				installName: "protoc",
 			},
 			test: exec.Command("./protoc", "--version"),
 		},
@@ -279,7 +301,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/snyk/cli/releases/download/v1.1292.1/snyk-linux")),
 			},
 			execBinary: ExecBinary{
-				name: "snyk",
+				name:        "snyk",
+				installName: "snyk",
 			},
 			test: exec.Command("./snyk", "version"),
 		},
@@ -291,7 +314,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/starship/starship/releases/download/v1.19.0/starship-x86_64-unknown-linux-gnu.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "starship",
+				name:        "starship",
+				installName: "starship",
 			},
 			test: exec.Command("./starship", "--version"),
 		},
@@ -303,7 +327,8 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 				downloadURL: must(url.Parse("https://github.com/viaduct-ai/kustomize-sops/releases/download/v4.3.2/ksops_4.3.2_Linux_x86_64.tar.gz")),
 			},
 			execBinary: ExecBinary{
-				name: "ksops",
+				name:        "ksops",
+				installName: "ksops",
 			},
 			test: exec.Command("test", "-f", "./ksops"),
 		},
@@ -321,23 +346,34 @@ func TestApplicationServiceForLinuxAmd64(t *testing.T) {
 			before := clone(t, tt.test)
 			require.Error(before.Run(), "executable binary was already installed")
 
-			assetRepository, err := newAssetRepository(tt.repo, io.Discard)
+			assetRepository, err := newAssetRepository(tt.repo, Config{}, io.Discard)
 			require.NoError(err)
-			execBinaryRepository := newExecBinaryRepository(dir)
-			app := newApplicationService(assetRepository, execBinaryRepository)
+			execBinaryRepository, err := newExecBinaryRepository(tt.repo, dir, "")
+			require.NoError(err)
+			repo, err := parseRepository(tt.repo)
+			require.NoError(err)
+			stateRepository := newStateRepository(filepath.Join(dir, ".state.json"))
+			app := newApplicationService(repo, assetRepository, execBinaryRepository, stateRepository)
 
 			ctx := context.Background()
 
-			asset, execBinary, err := app.find(ctx, tt.tag, defaultPatterns)
+			asset, execBinary, err := app.find(ctx, tt.tag, defaultPatterns, "")
 			require.NoError(err)
-			require.Equal(tt.asset, asset)
+			require.Equal(tt.asset.id, asset.id)
+			require.Equal(tt.asset.downloadURL, asset.downloadURL)
 			require.Equal(tt.execBinary, execBinary)
 
-			err = app.install(ctx, asset, execBinary)
+			result, err := app.install(ctx, tt.tag, asset, execBinary, InstallOptions{})
 			require.NoError(err)
+			require.NoError(result.archMismatch)
 
 			after := clone(t, tt.test)
 			require.NoError(after.Run())
+
+			records, err := stateRepository.list()
+			require.NoError(err)
+			require.Len(records, 1)
+			require.Equal(result.record, records[0])
 		})
 	}
 }
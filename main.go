@@ -1,68 +1,342 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/cli/go-gh/v2/pkg/prompter"
+	"github.com/cli/go-gh/v2/pkg/term"
 	"github.com/spf13/cobra"
 )
 
-func runE(ctx context.Context, repo string, tag string, patterns map[string]string, dir string) error {
-	assetRepository, err := newAssetRepository(repo, os.Stdout)
-	if err != nil {
-		return err
+func runE(ctx context.Context, targets []InstallTarget, dir string, store string, statePath string, noNotes bool, dryRun bool, extract bool, output string, jobs int, config Config) error {
+	out := os.Stdout
+	if output != "text" {
+		out = os.Stderr
+	}
+	var progressBar io.Writer = out
+	var pool *ProgressPool
+	if len(targets) > 1 {
+		pool = newProgressPool(out, term.IsTerminal(out))
+		progressBar = pool
 	}
-	execBinaryRepository := newExecBinaryRepository(dir)
-	app := newApplicationService(assetRepository, execBinaryRepository)
 
-	asset, execBinary, err := app.find(ctx, tag, patterns)
-	if err != nil {
-		return err
+	reports := make([]*InstallReport, len(targets))
+	installations := make([]*Installation, len(targets))
+	errs := make([]error, len(targets))
+	forEach(len(targets), jobs, func(i int) {
+		reports[i] = newInstallReport(targets[i].repo, dir)
+		installations[i], errs[i] = resolveInstallation(ctx, targets[i], reports[i], dir, store, statePath, dryRun, config, progressBar)
+	})
+
+	pending := []int{}
+	for i := range installations {
+		if errs[i] == nil && reports[i].Status == "" {
+			pending = append(pending, i)
+		}
+	}
+	switch {
+	case len(pending) == 0:
+	case dryRun:
+		forEach(len(pending), jobs, func(j int) {
+			errs[pending[j]] = installations[pending[j]].plan(ctx, extract)
+		})
+	default:
+		confirmed := []*Installation{}
+		for _, i := range pending {
+			confirmed = append(confirmed, installations[i])
+		}
+		confirm, err := confirmInstallations(ctx, out, confirmed, noNotes, config)
+		switch {
+		case err != nil:
+			for _, i := range pending {
+				errs[i] = err
+			}
+		case !confirm:
+			for _, i := range pending {
+				reports[i].Status = statusCancelled
+			}
+		default:
+			forEach(len(pending), jobs, func(j int) {
+				errs[pending[j]] = installations[pending[j]].install(ctx)
+			})
+		}
+	}
+	if pool != nil {
+		pool.stop()
+	}
+
+	failures := []error{}
+	for i, report := range reports {
+		report.finish(errs[i])
+		if errs[i] != nil {
+			failures = append(failures, fmt.Errorf("failed to install from %s: %w", report.Repo, errs[i]))
+		}
+	}
+	err := errors.Join(failures...)
+	if len(targets) == 1 {
+		err = errs[0]
 	}
 
-	prompt := fmt.Sprintf("Do you want to install %s from %s ?", execBinary.name, asset.downloadURL.String())
-	confirm, err := prompter.New(os.Stdin, os.Stdout, os.Stderr).Confirm(prompt, true)
-	if !confirm || err != nil {
+	if output == "text" {
+		for _, report := range reports {
+			report.printText(os.Stdout, os.Stderr)
+		}
 		return err
 	}
+	var v any = reports
+	if len(reports) == 1 {
+		v = reports[0]
+	}
+	if writeErr := writeReport(os.Stdout, output, v); writeErr != nil {
+		return writeErr
+	}
+	return err
+}
+
+// confirmInstallations writes release notes of given installations into w and asks user whether to install them.
+func confirmInstallations(ctx context.Context, w *os.File, installations []*Installation, noNotes bool, config Config) (bool, error) {
+	if !noNotes {
+		for _, installation := range installations {
+			if installation.run == nil {
+				printReleaseNotes(ctx, w, installation.repo, config, installation.from, Release{tag: installation.tag})
+			}
+		}
+	}
 
-	return app.install(ctx, asset, execBinary)
+	prompt := installations[0].prompt()
+	if len(installations) > 1 {
+		for _, installation := range installations {
+			fmt.Fprintf(w, "%s (%s) from %s\n", installation.execBinary.installName, installation.tag, installation.asset.downloadURL) // nolint:errcheck
+		}
+		prompt = fmt.Sprintf("Do you want to install %d executable binaries?", len(installations))
+	}
+	return prompter.New(os.Stdin, w, os.Stderr).Confirm(prompt, true)
+}
+
+// printReleaseNotes writes release notes of GitHub releases which are newer than from and not newer than to.
+// Failure to fetch release notes is reported as warning because it shouldn't prevent installation.
+func printReleaseNotes(ctx context.Context, w io.Writer, repo Repository, config Config, from Release, to Release) {
+	releaseRepository, err := newReleaseRepository(repo, config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to fetch release notes of %s: %s\n", repo, err) // nolint:errcheck
+		return
+	}
+	notes, err := releaseRepository.notes(ctx, from, to)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to fetch release notes of %s: %s\n", repo, err) // nolint:errcheck
+		return
+	}
+	if err := renderReleaseNotes(w, notes, term.FromEnv().IsColorEnabled()); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to print release notes of %s: %s\n", repo, err) // nolint:errcheck
+	}
 }
 
 func main() {
 	var (
-		repo     string
-		tag      string
-		patterns map[string]string
-		dir      string
+		repos        []string
+		manifestPath string
+		jobs         int
+		tag          string
+		selector     WorkflowRunSelector
+		patterns     map[string]string
+		installName  string
+		dir          string
+		versioned    bool
+		store        string
+		opts         InstallOptions
+		configPath   string
+		statePath    string
+		noNotes      bool
+		dryRun       bool
+		extract      bool
+		companions   bool
+		shareDir     string
+		tree         bool
+		treeOpts     TreeOptions
+		output       string
+		token        string
 	)
 
 	command := &cobra.Command{
-		Use:   "gh-release-install",
+		Use:   "gh-release-install [<repo>[@<tag> | @latest | @<version constraint>]...]",
 		Short: "Install an executable binary from a GitHub release asset.",
-		RunE: func(cmd *cobra.Command, _ []string) error {
-			return runE(cmd.Context(), repo, tag, patterns, dir)
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !versioned {
+				store = ""
+			}
+			if output != "text" && !slices.Contains(outputFormats, output) {
+				return fmt.Errorf("output format must be one of text, %s: %s", strings.Join(outputFormats, ", "), output)
+			}
+			if tree && versioned {
+				return errors.New(`flags "tree" and "versioned" can't be used together`)
+			}
+			if treeOpts.strip < 0 {
+				return fmt.Errorf("strip components must not be negative: %d", treeOpts.strip)
+			}
+			if opts.limits.maxDepth < 1 || opts.limits.maxSize < 1 || opts.limits.maxRatio <= 0 || opts.limits.timeout <= 0 {
+				return errors.New("limits on extracting release asset must be positive")
+			}
+			if jobs < 1 {
+				return fmt.Errorf("jobs must be positive: %d", jobs)
+			}
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return err
+			}
+
+			targets := []InstallTarget{}
+			if manifestPath != "" {
+				manifest, err := loadManifest(manifestPath)
+				if err != nil {
+					return err
+				}
+				for _, tool := range manifest.Tools {
+					target := InstallTarget{
+						repo:        tool.Repo,
+						tag:         tool.Tag,
+						patterns:    tool.Patterns,
+						installName: tool.Name,
+					}
+					if target.tag == "" {
+						target.tag = defaultTag(tool.Repo)
+					}
+					if target.patterns == nil {
+						target.patterns = patterns
+					}
+					targets = append(targets, target)
+				}
+			}
+			for _, arg := range args {
+				target, err := newArgumentTarget(arg, tag, selector)
+				if err != nil {
+					return err
+				}
+				target.patterns = patterns
+				target.installName = installName
+				targets = append(targets, target)
+			}
+			if (manifestPath == "" && len(args) == 0) || cmd.Flags().Changed("repo") {
+				for _, repo := range repos {
+					targets = append(targets, InstallTarget{
+						repo:        repo,
+						tag:         tag,
+						selector:    selector,
+						patterns:    patterns,
+						installName: installName,
+					})
+				}
+			}
+			if len(targets) == 0 {
+				return errors.New(`repositories must be given by arguments, flag "repo" or flag "manifest"`)
+			}
+			if len(targets) > 1 && selector.selected() {
+				return errors.New(`flags "run", "pr" and "branch" can be used only with single repository`)
+			}
+
+			for i := range targets {
+				target := &targets[i]
+				if target.tag == "" && !target.selector.selected() {
+					if _, ref, err := parseOCIReference(target.repo); err == nil && ref != "" {
+						target.tag = ref
+					} else {
+						return errors.New(`one of flags "tag", "run", "pr" and "branch" must be set`)
+					}
+				}
+				repoName := target.repo
+				if r, err := parseRepository(target.repo); err == nil {
+					repoName = r.String() // Tag of OCI reference is dropped to look up tool config.
+					if token != "" {
+						config.setFlagToken(r.host, token)
+					}
+				}
+				target.opts = opts
+				if companions {
+					target.opts.shareDir = cmp.Or(shareDir, defaultShareDir(dir))
+				}
+				if tree {
+					target.opts.tree = &TreeOptions{
+						prefix: cmp.Or(treeOpts.prefix, defaultPrefix(dir)),
+						strip:  treeOpts.strip,
+						links:  treeOpts.links,
+					}
+				}
+				toolConfig := config.tool(repoName)
+				if !cmd.Flags().Changed("verify-run") {
+					target.opts.verifyRun = toolConfig.VerifyRun
+				}
+				if !cmd.Flags().Changed("verify-version") {
+					target.opts.verifyVersion = toolConfig.VerifyVersion
+				}
+				if !cmd.Flags().Changed("version-probe") {
+					target.opts.versionProbe = toolConfig.VersionProbe
+				}
+			}
+			return runE(cmd.Context(), targets, dir, store, statePath, noNotes, dryRun || extract, extract, output, jobs, config)
 		},
 		SilenceUsage: true,
 	}
 
-	currentRepositoryName := ""
+	currentRepositoryNames := []string{}
 	if r, err := currentRepository(); err == nil {
-		currentRepositoryName = fmt.Sprintf("%s/%s/%s", r.host, r.owner, r.name)
+		currentRepositoryNames = append(currentRepositoryNames, r.String())
 	}
 
-	command.Flags().StringVarP(&repo, "repo", "R", currentRepositoryName, "GitHub repository name. This should be [HOST/]OWNER/REPO format.")
-	command.Flags().StringVar(&tag, "tag", "", "GitHub release tag.")
+	command.Flags().StringArrayVarP(&repos, "repo", "R", currentRepositoryNames, "Repository name. This should be [HOST/]OWNER/REPO format, HOST/GROUP/SUBGROUP/REPO format for project in GitLab subgroup or oci://REGISTRY/NAMESPACE/REPO[:TAG] format for OCI artifact. Host other than GitHub can be configured in configuration file. This can be repeated to install multiple executable binaries.")
+	command.Flags().StringVarP(&manifestPath, "manifest", "f", "", "Path of manifest file which lists executable binaries to install.")
+	command.Flags().IntVarP(&jobs, "jobs", "j", 4, "Number of executable binaries resolved and downloaded concurrently.")
+	command.Flags().StringVar(&tag, "tag", "", "Release tag. \"latest\" means the latest release. Other value is installed as literal tag. Version constraint such as \"~3.16\", \"^1.2\" or \">=1.2, <2\" is accepted only in <repo>@<version constraint> argument. This can be omitted if --repo is OCI reference with tag.")
+	command.Flags().Int64Var(&selector.runID, "run", 0, "ID of GitHub Actions workflow run to install executable binary from its artifact instead of release.")
+	command.Flags().IntVar(&selector.pullRequest, "pr", 0, "Number of pull request to install executable binary from artifact of the latest successful workflow run for it instead of release.")
+	command.Flags().StringVar(&selector.branch, "branch", "", "Branch to install executable binary from artifact of the latest successful workflow run on it instead of release.")
+	command.Flags().StringVar(&selector.workflow, "workflow", "", "Workflow file name such as \"build.yml\" to narrow down workflow runs for --pr or --branch.")
 	command.Flags().StringToStringVar(&patterns, "pattern", defaultPatterns, "Map whose key should be regular expressions of GitHub release asset download URL to download and value should be templates of executable binary name to install.")
+	command.Flags().StringVar(&installName, "name", "", "Template of executable binary name to install as. This can refer to values of capturing groups in pattern, \"Name\", \"Tag\" and \"SemVer\". (default same as executable binary name in GitHub release asset)")
 	command.Flags().StringVarP(&dir, "dir", "D", ".", "Directory where executable binary will be installed into.")
+	command.Flags().BoolVar(&opts.allowArchMismatch, "allow-arch-mismatch", false, "Install executable binary even if it was built for platform other than "+defaultPlatform.String()+".")
+	command.Flags().StringVar(&opts.verifyRun, "verify-run", "", "Command to run freshly installed executable binary to verify it works, such as 'terraform version'. Its first word is replaced with path of installed executable binary. Previous executable binary is restored if it fails.")
+	command.Flags().BoolVar(&opts.verifyVersion, "verify-version", false, "Require output of --verify-run command to contain release tag or semantic version.")
+	command.Flags().DurationVar(&opts.verifyTimeout, "verify-timeout", 30*time.Second, "Timeout of --verify-run command.")
+	command.Flags().StringVar(&opts.versionProbe, "version-probe", "", "Command to run executable binary which is already installed to check its version, such as 'terraform version'. Installation is skipped if its output contains release tag.")
+	command.Flags().IntVar(&opts.limits.maxDepth, "max-extract-depth", defaultExtractLimits.maxDepth, "Maximum number of nested archives and compressions in release asset.")
+	command.Flags().Int64Var(&opts.limits.maxSize, "max-extract-size", defaultExtractLimits.maxSize, "Maximum size in bytes of content decompressed from each layer of release asset.")
+	command.Flags().Float64Var(&opts.limits.maxRatio, "max-compression-ratio", defaultExtractLimits.maxRatio, "Maximum ratio of decompressed size to compressed size of each layer of release asset, which rejects zip bombs.")
+	command.Flags().DurationVar(&opts.limits.timeout, "extract-timeout", defaultExtractLimits.timeout, "Timeout of extracting executable binary from release asset.")
+	command.Flags().BoolVar(&opts.force, "force", false, "Install executable binary even if it is already up to date.")
+	command.Flags().BoolVar(&noNotes, "no-notes", false, "Don't show release notes before confirming installation.")
+	command.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be written where without writing anything.")
+	command.Flags().BoolVar(&extract, "dry-run-extract", false, "Same as --dry-run but also download release asset and extract executable binary from it to confirm it can be installed.")
+	command.Flags().BoolVar(&companions, "companions", false, "Also install man pages, shell completions and licenses in archive into share directory. They are removed when executable binary is uninstalled.")
+	command.Flags().StringVar(&shareDir, "share-dir", "", "Share directory where --companions installs files into, such as man/man1 and bash-completion/completions. (default \"<prefix>/share\" if --dir is \"<prefix>/bin\", otherwise XDG data directory)")
+	command.Flags().BoolVar(&tree, "tree", false, "Extract whole archive into \"<prefix>/opt/<owner>/<repo>/<tag>\" and install symbolic link to executable binary in it into directory, for tools which need files shipped with them.")
+	command.Flags().StringVar(&treeOpts.prefix, "prefix", "", "Prefix which --tree extracts archive under. (default \"<prefix>\" if --dir is \"<prefix>/bin\", otherwise parent of XDG data directory)")
+	command.Flags().IntVar(&treeOpts.strip, "strip-components", 0, "Number of leading path components removed from file names in archive when --tree extracts it.")
+	command.Flags().StringArrayVar(&treeOpts.links, "link", nil, "Path of additional executable binary in tree, relative to its root, to install symbolic link to into directory with --tree. This can be repeated.")
+	command.Flags().BoolVar(&versioned, "versioned", false, "Keep each version of executable binary in store and install symbolic link to it into directory.")
+	command.Flags().StringVar(&store, "store", defaultStore(), "Directory where each version of executable binary is kept when --versioned is set.")
+	command.Flags().StringVarP(&output, "output", "o", "text", "Output format of result. One of \"text\", \"json\" and \"yaml\". Progress, release notes and prompts are written into stderr unless this is \"text\".")
+	command.Flags().StringVar(&token, "token", "", "Access token for GitHub API. This takes precedence over GH_TOKEN, GITHUB_TOKEN, gh configuration and GitHub App in configuration file.")
+	command.Flags().StringVar(&configPath, "config", defaultConfigPath(), "Path of configuration file.")
+	command.Flags().StringVar(&statePath, "state", defaultStatePath(), "Path of file which records of installed executable binaries are stored into.")
 
-	if err := command.MarkFlagRequired("tag"); err != nil {
-		panic(err)
-	}
+	command.MarkFlagsMutuallyExclusive("tag", "run", "pr", "branch")
+	command.MarkFlagsMutuallyExclusive("run", "workflow")
+
+	command.AddCommand(newUseCommand(), newRollbackCommand(), newListCommand(), newInfoCommand(), newUninstallCommand(), newUpgradeCommand())
 
 	if err := command.ExecuteContext(context.Background()); err != nil {
+		var available *UpgradesAvailableError
+		if errors.As(err, &available) {
+			os.Exit(exitUpgradesAvailable)
+		}
 		os.Exit(1)
 	}
 }
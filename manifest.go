@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is a list of executable binaries to install at once.
+type Manifest struct {
+	// Tools are executable binaries to install.
+	Tools []ManifestTool `yaml:"tools"`
+}
+
+// ManifestTool is an executable binary to install listed in [Manifest].
+type ManifestTool struct {
+	// Repo is a repository name in [HOST/]OWNER/REPO format or OCI reference.
+	Repo string `yaml:"repo"`
+
+	// Tag is a release tag. "latest" means the latest release. This defaults to tag of OCI reference or "latest".
+	Tag string `yaml:"tag"`
+
+	// Patterns are same as --pattern flag. This defaults to --pattern flag.
+	Patterns map[string]string `yaml:"patterns"`
+
+	// Name is a template of executable binary name to install as, same as --name flag.
+	Name string `yaml:"name"`
+}
+
+// loadManifest reads [Manifest] from given path.
+func loadManifest(path string) (Manifest, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Manifest{}, err
+	}
+	var manifest Manifest
+	if err := yaml.Unmarshal(b, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	for i, tool := range manifest.Tools {
+		if tool.Repo == "" {
+			return Manifest{}, fmt.Errorf("repo of tools[%d] in manifest %s was empty", i, path)
+		}
+	}
+	return manifest, nil
+}
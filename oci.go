@@ -0,0 +1,326 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Media types of OCI and Docker manifests.
+const (
+	ociImageIndexMediaType      = "application/vnd.oci.image.index.v1+json"
+	ociImageManifestMediaType   = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+	dockerManifestMediaType     = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// ociTitleAnnotation is an annotation which contains file name of layer.
+const ociTitleAnnotation = "org.opencontainers.image.title"
+
+// ociDescriptor is a descriptor of content in OCI registry.
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+	} `json:"platform,omitempty"`
+}
+
+// ociManifest is an OCI image index, OCI image manifest or their Docker equivalents.
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Manifests []ociDescriptor `json:"manifests"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// ociClient is a client for OCI distribution API of a repository in OCI registry.
+type ociClient struct {
+	client   *http.Client
+	baseURL  string
+	repo     Repository
+	username string
+	password string
+
+	mu    sync.Mutex
+	token string // bearer token obtained from registry's token service.
+}
+
+// newOCIClient returns a new [ociClient] object for given repository in OCI registry.
+// Registry is accessed over HTTPS unless it is on loopback address or base URL is configured.
+func newOCIClient(repo Repository, host HostConfig) *ociClient {
+	baseURL := host.APIURL
+	if baseURL == "" {
+		baseURL = "https://" + repo.host
+		if h, _, err := net.SplitHostPort(repo.host); err == nil && (h == "localhost" || net.ParseIP(h).IsLoopback()) {
+			baseURL = "http://" + repo.host
+		}
+	}
+	usernameEnv, passwordEnv := host.UsernameEnv, host.TokenEnv
+	if usernameEnv == "" {
+		usernameEnv = "OCI_USERNAME"
+	}
+	if passwordEnv == "" {
+		passwordEnv = "OCI_PASSWORD"
+	}
+	return &ociClient{
+		client:   host.httpClient(),
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		repo:     repo,
+		username: os.Getenv(usernameEnv),
+		password: os.Getenv(passwordEnv),
+	}
+}
+
+// name returns name of repository in OCI distribution API.
+func (c *ociClient) name() string {
+	return c.repo.owner + "/" + c.repo.name
+}
+
+// do sends request to registry. If registry requires authentication by bearer token, this obtains token from token service and retries request.
+func (c *ociClient) do(ctx context.Context, path string, accept []string) (*http.Response, error) {
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+		c.mu.Lock()
+		token := c.token
+		c.mu.Unlock()
+		switch {
+		case token != "":
+			req.Header.Set("Authorization", "Bearer "+token)
+		case c.username != "" || c.password != "":
+			req.SetBasicAuth(c.username, c.password)
+		}
+		return c.client.Do(req)
+	}
+
+	resp, err := send()
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, checkResponseAndKeepBody(resp)
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close() // nolint:errcheck
+	if err := c.authenticate(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	resp, err = send()
+	if err != nil {
+		return nil, err
+	}
+	return resp, checkResponseAndKeepBody(resp)
+}
+
+// checkResponseAndKeepBody returns an error if given HTTP response is not successful. Response body is closed only if error is returned.
+func checkResponseAndKeepBody(resp *http.Response) error {
+	if err := checkResponse(resp); err != nil {
+		resp.Body.Close() // nolint:errcheck
+		return err
+	}
+	return nil
+}
+
+// authenticate obtains bearer token from token service described in given WWW-Authenticate challenge.
+func (c *ociClient) authenticate(ctx context.Context, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return fmt.Errorf("authentication scheme of OCI registry %s was unsupported: %s", c.repo.host, challenge)
+	}
+	attrs := map[string]string{}
+	for _, param := range strings.Split(params, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+		attrs[strings.ToLower(k)] = strings.Trim(v, `"`)
+	}
+	realm, err := url.Parse(attrs["realm"])
+	if err != nil || realm.String() == "" {
+		return fmt.Errorf("realm of OCI registry %s was invalid: %s", c.repo.host, challenge)
+	}
+	q := realm.Query()
+	if attrs["service"] != "" {
+		q.Set("service", attrs["service"])
+	}
+	scope := attrs["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", c.name())
+	}
+	q.Set("scope", scope)
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if err := checkResponse(resp); err != nil {
+		return fmt.Errorf("failed to obtain token for OCI registry %s: %w", c.repo.host, err)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = body.Token
+	if c.token == "" {
+		c.token = body.AccessToken
+	}
+	return nil
+}
+
+// manifest returns manifest which has given tag or digest.
+func (c *ociClient) manifest(ctx context.Context, reference string) (ociManifest, error) {
+	resp, err := c.do(ctx, fmt.Sprintf("/v2/%s/manifests/%s", c.name(), reference), []string{ociImageIndexMediaType, ociImageManifestMediaType, dockerManifestListMediaType, dockerManifestMediaType})
+	if err != nil {
+		return ociManifest{}, err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	var m ociManifest
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return ociManifest{}, err
+	}
+	if m.MediaType == "" {
+		m.MediaType = resp.Header.Get("Content-Type")
+	}
+	return m, nil
+}
+
+// platformManifest returns manifest which has given tag. If it is an index, this returns manifest for given platform in it.
+func (c *ociClient) platformManifest(ctx context.Context, tag string, platform Platform) (ociManifest, error) {
+	m, err := c.manifest(ctx, tag)
+	if err != nil {
+		return ociManifest{}, err
+	}
+	if m.MediaType != ociImageIndexMediaType && m.MediaType != dockerManifestListMediaType && len(m.Manifests) == 0 {
+		return m, nil
+	}
+	for _, d := range m.Manifests {
+		if d.Platform != nil && d.Platform.OS == platform.os && d.Platform.Architecture == platform.arch {
+			return c.manifest(ctx, d.Digest)
+		}
+	}
+	return ociManifest{}, fmt.Errorf("%s:%s has no manifest for %s", c.repo, tag, platform)
+}
+
+// tags returns tags in repository.
+// Registry may list tags in pages, so this follows link to next page in Link header until it runs out.
+func (c *ociClient) tags(ctx context.Context) ([]string, error) {
+	tags := []string{}
+	for path := fmt.Sprintf("/v2/%s/tags/list", c.name()); path != ""; {
+		page, next, err := c.tagsPage(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page...)
+		if next == path {
+			break
+		}
+		path = next
+	}
+	return tags, nil
+}
+
+// tagsPage returns tags in a page at given path and path of next page. Path of next page is empty if this is the last page.
+func (c *ociClient) tagsPage(ctx context.Context, path string) ([]string, string, error) {
+	resp, err := c.do(ctx, path, []string{"application/json"})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	var body struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, "", err
+	}
+	link := nextLink(resp.Header.Get("Link"))
+	if link == "" {
+		return body.Tags, "", nil
+	}
+	current, err := url.Parse(c.baseURL + path)
+	if err != nil {
+		return nil, "", err
+	}
+	u, err := current.Parse(link)
+	if err != nil {
+		return nil, "", err
+	}
+	next, ok := strings.CutPrefix(u.String(), c.baseURL)
+	if !ok {
+		return nil, "", fmt.Errorf("next page of tags was out of OCI registry %s: %s", c.repo.host, u)
+	}
+	return body.Tags, next, nil
+}
+
+// nextLink returns URL whose relation type is "next" in given Link header, or empty string if there is no such URL.
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, _ := strings.Cut(link, ";")
+		target = strings.TrimSpace(target)
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key == "rel" && slices.Contains(strings.Fields(strings.Trim(value, `"`)), "next") {
+				return target[1 : len(target)-1]
+			}
+		}
+	}
+	return ""
+}
+
+// blob downloads blob which has given digest, writing progress bar into progressBar, and verifies its digest.
+func (c *ociClient) blob(ctx context.Context, digest string, progressBar io.Writer) ([]byte, error) {
+	algorithm, expected, ok := strings.Cut(digest, ":")
+	if !ok || algorithm != "sha256" {
+		return nil, fmt.Errorf("digest algorithm was unsupported: %s", digest)
+	}
+	resp, err := c.do(ctx, fmt.Sprintf("/v2/%s/blobs/%s", c.name(), digest), []string{"*/*"})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+
+	pr := newProgressReader(resp.Body, c.name(), resp.ContentLength, progressBar)
+	defer pr.Close() // nolint:errcheck
+
+	b, err := io.ReadAll(pr)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return nil, withCode(ErrChecksumMismatch, fmt.Errorf("digest of blob was mismatched: expected sha256:%s but got sha256:%s", expected, actual))
+	}
+	return b, nil
+}
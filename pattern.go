@@ -3,9 +3,12 @@ package main
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"path/filepath"
 	"regexp"
 	"slices"
 	"strconv"
+	"strings"
 	"text/template"
 )
 
@@ -54,8 +57,10 @@ func (p Pattern) priority() int {
 	return len(prefix)
 }
 
-// execute applies a template of executable binary name to values of capturing groups in regular expression of GitHub release asset download URL and returns [ExecBinary] object.
-func (p Pattern) execute(asset Asset) (ExecBinary, error) {
+// execute applies templates of executable binary name to values of capturing groups in regular expression of GitHub release asset download URL and returns [ExecBinary] object.
+// installName is a template of executable binary name to install as. This can refer to "Name", "Tag" and "SemVer" in addition to values of capturing groups.
+// If installName is nil, executable binary is installed as same name as one in GitHub release asset.
+func (p Pattern) execute(release Release, asset Asset, installName *template.Template) (ExecBinary, error) {
 	data := map[string]string{}
 	submatch := p.asset.FindStringSubmatch(asset.downloadURL.String())
 
@@ -75,9 +80,38 @@ func (p Pattern) execute(asset Asset) (ExecBinary, error) {
 		return ExecBinary{}, err
 	}
 
-	return ExecBinary{
-		name: b.String(),
-	}, nil
+	execBinary := ExecBinary{
+		name:        b.String(),
+		installName: b.String(),
+	}
+	if installName == nil {
+		return execBinary, nil
+	}
+
+	data["Name"] = execBinary.name
+	data["Tag"] = release.tag
+	data["SemVer"] = release.semVer()
+
+	b.Reset()
+	if err := installName.Execute(&b, data); err != nil {
+		return ExecBinary{}, err
+	}
+	execBinary.installName = b.String()
+
+	if execBinary.installName == "" || execBinary.installName == "." || execBinary.installName == ".." || strings.ContainsRune(execBinary.installName, filepath.Separator) {
+		return ExecBinary{}, fmt.Errorf("executable binary name to install as was invalid: %q", execBinary.installName)
+	}
+
+	return execBinary, nil
+}
+
+// parseInstallNameTemplate returns a new template of executable binary name to install as.
+// If given string is empty, this returns nil which means executable binary is installed as same name as one in GitHub release asset.
+func parseInstallNameTemplate(installName string) (*template.Template, error) {
+	if installName == "" {
+		return nil, nil
+	}
+	return template.New("InstallName").Parse(installName)
 }
 
 // findAssetAndPattern finds [Asset] and [Pattern] matching and returns them.
@@ -96,5 +130,5 @@ func findAssetAndPattern(assets []Asset, patterns []Pattern) (Asset, Pattern, er
 		}
 	}
 
-	return Asset{}, Pattern{}, errors.New("no assets match the pattern")
+	return Asset{}, Pattern{}, withCode(ErrNoMatchingAsset, errors.New("no assets match the pattern"))
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternExecute(t *testing.T) {
+	tests := []struct {
+		name        string
+		pattern     map[string]string
+		installName string
+		release     Release
+		asset       Asset
+		execBinary  ExecBinary
+	}{
+		{
+			name:        "DefaultInstallName",
+			pattern:     map[string]string{`^.+/(?P<name>kubectl)$`: "{{.name}}"},
+			installName: "",
+			release:     Release{tag: "v1.31.0"},
+			asset:       Asset{downloadURL: must(url.Parse("https://dl.k8s.io/release/v1.31.0/bin/linux/amd64/kubectl"))},
+			execBinary:  ExecBinary{name: "kubectl", installName: "kubectl"},
+		},
+		{
+			name:        "VersionSuffixedInstallName",
+			pattern:     map[string]string{`^.+/(?P<name>kubectl)$`: "{{.name}}"},
+			installName: "{{.Name}}-{{.SemVer}}",
+			release:     Release{tag: "v1.31.0"},
+			asset:       Asset{downloadURL: must(url.Parse("https://dl.k8s.io/release/v1.31.0/bin/linux/amd64/kubectl"))},
+			execBinary:  ExecBinary{name: "kubectl", installName: "kubectl-1.31.0"},
+		},
+		{
+			name:        "RenamedInstallName",
+			pattern:     map[string]string{`https://cdn\.teleport\.dev/(?P<product>teleport)-v.+-linux-amd64-bin.tar.gz`: "tsh"},
+			installName: "{{.product}}-{{.Name}}",
+			release:     Release{tag: "v16.0.0"},
+			asset:       Asset{downloadURL: must(url.Parse("https://cdn.teleport.dev/teleport-v16.0.0-linux-amd64-bin.tar.gz"))},
+			execBinary:  ExecBinary{name: "tsh", installName: "teleport-tsh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			patterns, err := parsePatterns(tt.pattern)
+			require.NoError(err)
+			require.Len(patterns, 1)
+
+			installName, err := parseInstallNameTemplate(tt.installName)
+			require.NoError(err)
+
+			execBinary, err := patterns[0].execute(tt.release, tt.asset, installName)
+			require.NoError(err)
+			require.Equal(tt.execBinary, execBinary)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+// Platform represents an operating system and architecture which an executable binary runs on.
+type Platform struct {
+	os   string
+	arch string
+}
+
+// String returns platform in "OS/ARCH" format.
+func (p Platform) String() string {
+	return p.os + "/" + p.arch
+}
+
+// bits returns bit width of architecture of this platform.
+func (p Platform) bits() int {
+	switch p.arch {
+	case "386", "arm", "mips", "mipsle":
+		return 32
+	default:
+		return 64
+	}
+}
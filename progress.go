@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cheggaaa/pb/v3"
+)
+
+// progressRefreshRate is an interval to redraw progress bars in [ProgressPool].
+const progressRefreshRate = 200 * time.Millisecond
+
+// ProgressPool renders progress bars of concurrent downloads together, each on its own line.
+// It is passed to repositories as progressBar writer instead of terminal, and [newProgressReader] adds a progress bar to it.
+// Unlike [pb.Pool], progress bars can be added at any time and terminal is not switched into raw mode.
+// If output is not a terminal, progress bars are drawn only once when pool is stopped, without escape sequences to move cursor.
+type ProgressPool struct {
+	output   io.Writer
+	terminal bool // whether output is a terminal which progress bars can be redrawn on.
+
+	mu    sync.Mutex
+	bars  []*pb.ProgressBar
+	lines int // number of lines drawn last time, which are overwritten next time.
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// newProgressPool returns a new [ProgressPool] object which draws progress bars into given writer until it is stopped.
+// terminal tells whether output is a terminal.
+func newProgressPool(output io.Writer, terminal bool) *ProgressPool {
+	p := &ProgressPool{
+		output:   output,
+		terminal: terminal,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(progressRefreshRate)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if p.terminal {
+					p.draw()
+				}
+			case <-p.done:
+				p.draw()
+				return
+			}
+		}
+	}()
+	return p
+}
+
+// Write implements io.Writer. Given bytes are written above progress bars.
+func (p *ProgressPool) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	return p.output.Write(b)
+}
+
+// add adds a new progress bar named name whose total is given and returns it.
+func (p *ProgressPool) add(name string, total int64) *pb.ProgressBar {
+	bar := pb.New64(total).SetTemplate(pb.Full)
+	bar.Set(pb.Static, true)
+	bar.Set("prefix", name+" ")
+	bar.SetWidth(100)
+	bar.Start()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bars = append(p.bars, bar)
+	return bar
+}
+
+// stop draws progress bars last time and stops drawing them.
+func (p *ProgressPool) stop() {
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
+	<-p.stopped
+}
+
+// draw redraws all progress bars, overwriting ones drawn last time.
+// If output is not a terminal, this just writes them line by line.
+func (p *ProgressPool) draw() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.bars) == 0 {
+		return
+	}
+	var b strings.Builder
+	if !p.terminal {
+		for _, bar := range p.bars {
+			fmt.Fprintln(&b, bar.String()) // nolint:errcheck
+		}
+		fmt.Fprint(p.output, b.String()) // nolint:errcheck
+		return
+	}
+	if p.lines > 0 {
+		fmt.Fprintf(&b, "\033[%dA", p.lines) // nolint:errcheck
+	}
+	for _, bar := range p.bars {
+		fmt.Fprintf(&b, "\r%s\033[K\n", bar.String()) // nolint:errcheck
+	}
+	fmt.Fprint(p.output, b.String()) // nolint:errcheck
+	p.lines = len(p.bars)
+}
+
+// clear erases progress bars drawn last time so that other output is not mixed with them. They are drawn again next time.
+func (p *ProgressPool) clear() {
+	if p.lines == 0 {
+		return
+	}
+	fmt.Fprintf(p.output, "\033[%dA\r\033[J", p.lines) // nolint:errcheck
+	p.lines = 0
+}
+
+// newProgressReader returns a reader which writes progress bar into progressBar while reading r. Closing it finishes progress bar.
+// If progressBar is [ProgressPool], progress bar named name is added to it instead of being written alone.
+func newProgressReader(r io.Reader, name string, total int64, progressBar io.Writer) io.ReadCloser {
+	if pool, ok := progressBar.(*ProgressPool); ok {
+		return pool.add(name, total).NewProxyReader(r)
+	}
+	return pb.Full.Start64(total).SetWriter(progressBar).NewProxyReader(r)
+}
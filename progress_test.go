@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a [bytes.Buffer] safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestProgressPool(t *testing.T) {
+	tests := []struct {
+		name     string
+		terminal bool
+	}{
+		{
+			name:     "Terminal",
+			terminal: true,
+		},
+		{
+			name:     "NotTerminal",
+			terminal: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output syncBuffer
+			pool := newProgressPool(&output, tt.terminal)
+
+			var wg sync.WaitGroup
+			for _, name := range []string{"gh_linux_amd64.tar.gz", "jq-linux-amd64"} {
+				wg.Go(func() {
+					r := newProgressReader(strings.NewReader(strings.Repeat("x", 1024)), name, 1024, pool)
+					b, err := io.ReadAll(r)
+					require.NoError(t, err)
+					require.Len(t, b, 1024)
+					require.NoError(t, r.Close())
+				})
+			}
+			wg.Wait()
+			pool.stop()
+
+			require.Contains(t, output.String(), "gh_linux_amd64.tar.gz")
+			require.Contains(t, output.String(), "jq-linux-amd64")
+			if !tt.terminal {
+				require.NotContains(t, output.String(), "\033[")
+				require.Equal(t, 2, strings.Count(output.String(), "\n"), "progress bars should be drawn only once")
+			}
+		})
+	}
+}
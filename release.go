@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"strings"
 
 	"golang.org/x/mod/semver"
@@ -21,3 +24,53 @@ func (r Release) semVer() string {
 	}
 	return ""
 }
+
+// prerelease returns true if semantic version of this release has prerelease suffix such as "-rc.1".
+func (r Release) prerelease() bool {
+	v := r.semVer()
+	return v != "" && semver.Prerelease("v"+v) != ""
+}
+
+// newerThan returns true if this release is newer than given one.
+// Releases are compared as semantic version if both of them have it. Otherwise, this returns true if their tags are different.
+func (r Release) newerThan(other Release) bool {
+	v1, v2 := r.semVer(), other.semVer()
+	if v1 == "" || v2 == "" {
+		return r.tag != other.tag
+	}
+	return semver.Compare("v"+v1, "v"+v2) > 0
+}
+
+// ReleaseRepository is an interface about repository for [Release].
+type ReleaseRepository interface {
+	latest(ctx context.Context) (Release, error)
+	releases(ctx context.Context) ([]Release, error)
+	notes(ctx context.Context, from Release, to Release) ([]ReleaseNote, error)
+}
+
+// newReleaseRepository returns a new [ReleaseRepository] object for given repository.
+// [OCIReleaseRepository] object is returned for repository in OCI registry.
+// [ExternalAssetRepository] object is returned if release source of repository is configured.
+// Otherwise, [ReleaseRepository] object is chosen by type of repository's host in configuration.
+func newReleaseRepository(repo Repository, config Config) (ReleaseRepository, error) {
+	if repo.scheme == "oci" {
+		return newOCIReleaseRepository(repo, config.host(repo.host)), nil
+	}
+	if source := config.tool(repo.String()).Source; source != nil {
+		return newExternalAssetRepositoryFromSource(*source, config.httpClient(), io.Discard)
+	}
+	host := config.host(repo.host)
+	if err := checkNamespace(repo, host); err != nil {
+		return nil, err
+	}
+	switch host.Type {
+	case "github":
+		return newGitHubReleaseRepository(repo, host)
+	case "gitlab":
+		return newGitLabReleaseRepository(repo, host), nil
+	case "gitea":
+		return newGiteaReleaseRepository(repo, host), nil
+	default:
+		return nil, fmt.Errorf("type of host %s was unknown: %s", repo.host, host.Type)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// VersionConstraint is a constraint on semantic version of release, such as "~3.16", "^1.2" or ">=1.2, <2".
+// Comma-separated clauses must be satisfied all. Prereleases never satisfy constraint.
+type VersionConstraint struct {
+	clauses []versionClause
+}
+
+// versionClause is a comparison of semantic version with operator such as ">=" or "<".
+type versionClause struct {
+	op      string
+	version string // semantic version with "v" prefix.
+}
+
+// isVersionConstraint returns true if given release selector is version constraint rather than release tag.
+// Characters which start version constraint are not allowed in Git tags, or are unlikely to be used in them.
+func isVersionConstraint(s string) bool {
+	return strings.IndexAny(s, "~^<>=") == 0 || strings.Contains(s, ",")
+}
+
+// parseVersionConstraint parses given string as [VersionConstraint].
+// "~X.Y.Z" allows patch updates, "^X.Y.Z" allows updates which don't change the leftmost non-zero number, and "=", ">", ">=", "<" and "<=" compare versions.
+func parseVersionConstraint(s string) (VersionConstraint, error) {
+	c := VersionConstraint{}
+	for _, clause := range strings.Split(s, ",") {
+		clause = strings.TrimSpace(clause)
+		op := clause[:len(clause)-len(strings.TrimLeft(clause, "~^<>="))]
+		v := strings.TrimSpace(strings.TrimPrefix(clause, op))
+		numbers, err := parseVersionNumbers(v)
+		if err != nil {
+			return VersionConstraint{}, fmt.Errorf("version constraint was invalid: %s: %w", s, err)
+		}
+		lower := formatVersion(numbers[0], numbers[1], numbers[2])
+		switch op {
+		case "~":
+			upper := formatVersion(numbers[0]+1, 0, 0)
+			if strings.Contains(v, ".") {
+				upper = formatVersion(numbers[0], numbers[1]+1, 0)
+			}
+			c.clauses = append(c.clauses, versionClause{op: ">=", version: lower}, versionClause{op: "<", version: upper})
+		case "^":
+			parts := strings.Count(v, ".") + 1
+			var upper string
+			switch {
+			case numbers[0] > 0 || parts == 1:
+				upper = formatVersion(numbers[0]+1, 0, 0)
+			case numbers[1] > 0 || parts == 2:
+				upper = formatVersion(0, numbers[1]+1, 0)
+			default:
+				upper = formatVersion(0, 0, numbers[2]+1)
+			}
+			c.clauses = append(c.clauses, versionClause{op: ">=", version: lower}, versionClause{op: "<", version: upper})
+		case "", "=", ">", ">=", "<", "<=":
+			if op == "" {
+				op = "="
+			}
+			c.clauses = append(c.clauses, versionClause{op: op, version: lower})
+		default:
+			return VersionConstraint{}, fmt.Errorf("operator of version constraint was unsupported: %s", clause)
+		}
+	}
+	return c, nil
+}
+
+// parseVersionNumbers parses "[v]X[.Y[.Z]]" into major, minor and patch numbers. Omitted numbers are zero.
+func parseVersionNumbers(v string) ([3]int, error) {
+	numbers := [3]int{}
+	parts := strings.Split(strings.TrimPrefix(strings.TrimPrefix(v, "v"), "V"), ".")
+	if len(parts) > 3 {
+		return numbers, fmt.Errorf("version has too many parts: %s", v)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return numbers, fmt.Errorf("version was not numeric: %s", v)
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// formatVersion returns semantic version with "v" prefix.
+func formatVersion(major int, minor int, patch int) string {
+	return fmt.Sprintf("v%d.%d.%d", major, minor, patch)
+}
+
+// match returns true if semantic version of given release satisfies this constraint.
+func (c VersionConstraint) match(release Release) bool {
+	v := release.semVer()
+	if v == "" || release.prerelease() {
+		return false
+	}
+	for _, clause := range c.clauses {
+		cmp := semver.Compare("v"+v, clause.version)
+		ok := false
+		switch clause.op {
+		case "=":
+			ok = cmp == 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveRelease resolves release selector into release tag.
+// Selector is "latest", [VersionConstraint] which is resolved into the newest release satisfying it, or release tag which is returned as is.
+func resolveRelease(ctx context.Context, releaseRepository ReleaseRepository, selector string) (Release, error) {
+	if selector == "latest" {
+		return releaseRepository.latest(ctx)
+	}
+	if !isVersionConstraint(selector) {
+		return Release{tag: selector}, nil
+	}
+	constraint, err := parseVersionConstraint(selector)
+	if err != nil {
+		return Release{}, err
+	}
+	releases, err := releaseRepository.releases(ctx)
+	if err != nil {
+		return Release{}, err
+	}
+	newest := Release{}
+	for _, release := range releases {
+		if constraint.match(release) && (newest.tag == "" || release.newerThan(newest)) {
+			newest = release
+		}
+	}
+	if newest.tag == "" {
+		return Release{}, withCode(ErrNotFound, fmt.Errorf("no releases satisfied version constraint %s", selector))
+	}
+	return newest, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionConstraintMatch(t *testing.T) {
+	tests := []struct {
+		constraint string
+		tag        string
+		match      bool
+	}{
+		{constraint: "~3.16", tag: "v3.16.0", match: true},
+		{constraint: "~3.16", tag: "v3.16.4", match: true},
+		{constraint: "~3.16", tag: "v3.17.0", match: false},
+		{constraint: "~3.16", tag: "v3.15.9", match: false},
+		{constraint: "~3", tag: "v3.99.0", match: true},
+		{constraint: "~3.16.2", tag: "v3.16.1", match: false},
+		{constraint: "^1.2", tag: "v1.9.0", match: true},
+		{constraint: "^1.2", tag: "v2.0.0", match: false},
+		{constraint: "^0.8.1", tag: "0.8.5", match: true},
+		{constraint: "^0.8.1", tag: "0.9.0", match: false},
+		{constraint: "^0.0.3", tag: "0.0.4", match: false},
+		{constraint: ">=1.2, <2", tag: "v1.5.0", match: true},
+		{constraint: ">=1.2, <2", tag: "v2.0.0", match: false},
+		{constraint: "=1.2.3", tag: "v1.2.3", match: true},
+		{constraint: "~3.16", tag: "v3.16.1-rc.1", match: false},
+		{constraint: "~3.16", tag: "nightly", match: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+"/"+tt.tag, func(t *testing.T) {
+			c, err := parseVersionConstraint(tt.constraint)
+			require.NoError(t, err)
+			require.Equal(t, tt.match, c.match(Release{tag: tt.tag}))
+		})
+	}
+}
+
+func TestParseVersionConstraintError(t *testing.T) {
+	for _, s := range []string{"~", "~3.x", "=>1.2", ">=1.2,", "~1.2.3.4"} {
+		t.Run(s, func(t *testing.T) {
+			_, err := parseVersionConstraint(s)
+			require.Error(t, err)
+		})
+	}
+}
+
+// fakeReleaseRepository is an in-memory [ReleaseRepository] for tests.
+type fakeReleaseRepository struct {
+	tags []string
+}
+
+func (r *fakeReleaseRepository) latest(_ context.Context) (Release, error) {
+	return Release{tag: r.tags[0]}, nil
+}
+
+func (r *fakeReleaseRepository) releases(_ context.Context) ([]Release, error) {
+	releases := []Release{}
+	for _, tag := range r.tags {
+		releases = append(releases, Release{tag: tag})
+	}
+	return releases, nil
+}
+
+func (r *fakeReleaseRepository) notes(_ context.Context, _ Release, _ Release) ([]ReleaseNote, error) {
+	return []ReleaseNote{}, nil
+}
+
+func TestResolveRelease(t *testing.T) {
+	repository := &fakeReleaseRepository{tags: []string{"v3.17.0", "v3.16.2", "v3.16.10", "v3.16.3", "v3.15.0"}}
+
+	tests := []struct {
+		selector string
+		tag      string
+		code     ErrorCode
+	}{
+		{selector: "latest", tag: "v3.17.0"},
+		{selector: "v3.15.0", tag: "v3.15.0"},
+		{selector: "~3.16", tag: "v3.16.10"},
+		{selector: "<3.16", tag: "v3.15.0"},
+		{selector: "~4.0", code: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			release, err := resolveRelease(context.Background(), repository, tt.selector)
+			if tt.code != "" {
+				require.Equal(t, tt.code, errorCode(err))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.tag, release.tag)
+		})
+	}
+}
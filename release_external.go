@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// defaultVersionPattern is a default regular expression to find versions in index.
+var defaultVersionPattern = regexp.MustCompile(`\d+\.\d+\.\d+`)
+
+// ExternalReleaseIndex is a source of releases hosted on server other than GitHub.
+// Versions are scraped from HTML or JSON index, or the latest version is read from JSON endpoint.
+type ExternalReleaseIndex struct {
+	client         *http.Client
+	index          string
+	versionPattern *regexp.Regexp
+	latest         string
+	latestField    string
+	tagPrefix      string
+}
+
+// newExternalReleaseIndex returns a new [ExternalReleaseIndex] object.
+func newExternalReleaseIndex(source SourceConfig, client *http.Client) (*ExternalReleaseIndex, error) {
+	if source.Index == "" && source.Latest == "" {
+		return nil, errors.New("either index or latest of source must be configured")
+	}
+	pattern := defaultVersionPattern
+	if source.VersionPattern != "" {
+		p, err := regexp.Compile(source.VersionPattern)
+		if err != nil {
+			return nil, err
+		}
+		pattern = p
+	}
+	return &ExternalReleaseIndex{
+		client:         client,
+		index:          source.Index,
+		versionPattern: pattern,
+		latest:         source.Latest,
+		latestField:    source.LatestField,
+		tagPrefix:      source.TagPrefix,
+	}, nil
+}
+
+// newExternalAssetRepositoryFromSource returns a new [ExternalAssetRepository] object which lists release assets and resolves releases by given source configuration.
+func newExternalAssetRepositoryFromSource(source SourceConfig, client *http.Client, progressBar io.Writer) (*ExternalAssetRepository, error) {
+	if source.Type != "http" {
+		return nil, fmt.Errorf("type of source was unknown: %s", source.Type)
+	}
+	templates := []ExternalAssetTemplate{}
+	for _, s := range source.Assets {
+		tmpl, err := parseExternalAssetTemplate(s)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, tmpl)
+	}
+	index, err := newExternalReleaseIndex(source, client)
+	if err != nil {
+		return nil, err
+	}
+	r := newExternalAssetRepository(templates, client, progressBar)
+	r.index = index
+	return r, nil
+}
+
+// versions scrapes versions from index and returns them.
+func (i *ExternalReleaseIndex) versions(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, i.index, nil)
+	if err != nil {
+		return nil, err
+	}
+	b, err := download(i.client, req, 0, io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	for _, m := range i.versionPattern.FindAllStringSubmatch(string(b), -1) {
+		v := m[0]
+		if len(m) > 1 {
+			v = m[1]
+		}
+		if !slices.Contains(versions, v) {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+// latestVersion returns the latest version.
+// This reads JSON endpoint if it is configured. Otherwise, this returns the newest stable semantic version in index.
+func (i *ExternalReleaseIndex) latestVersion(ctx context.Context) (string, error) {
+	if i.latest != "" {
+		var v any
+		if err := getJSON(ctx, i.client, i.latest, http.Header{}, &v); err != nil {
+			return "", err
+		}
+		return jsonField(v, i.latestField)
+	}
+
+	versions, err := i.versions(ctx)
+	if err != nil {
+		return "", err
+	}
+	latest := ""
+	for _, v := range versions {
+		sv := "v" + strings.TrimPrefix(v, "v")
+		if !semver.IsValid(sv) || semver.Prerelease(sv) != "" {
+			continue
+		}
+		if latest == "" || semver.Compare(sv, "v"+strings.TrimPrefix(latest, "v")) > 0 {
+			latest = v
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no versions were found in %s", i.index)
+	}
+	return latest, nil
+}
+
+// jsonField returns string value at given dot-separated path in decoded JSON value.
+// Elements of array can be referred by index such as "versions.0".
+func jsonField(v any, path string) (string, error) {
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			switch t := v.(type) {
+			case map[string]any:
+				v = t[key]
+			case []any:
+				i, err := strconv.Atoi(key)
+				if err != nil || i < 0 || i >= len(t) {
+					return "", fmt.Errorf("field %s was not found in JSON", path)
+				}
+				v = t[i]
+			default:
+				return "", fmt.Errorf("field %s was not found in JSON", path)
+			}
+		}
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("field %s in JSON was not string", path)
+	}
+	return s, nil
+}
+
+// latest returns the latest release resolved by release index.
+func (r *ExternalAssetRepository) latest(ctx context.Context) (Release, error) {
+	if r.index == nil {
+		return Release{}, errors.New("source of releases was not configured")
+	}
+	v, err := r.index.latestVersion(ctx)
+	if err != nil {
+		return Release{}, err
+	}
+	return Release{
+		tag: r.index.tagPrefix + strings.TrimPrefix(v, r.index.tagPrefix),
+	}, nil
+}
+
+// releases returns releases of versions found in release index.
+func (r *ExternalAssetRepository) releases(ctx context.Context) ([]Release, error) {
+	if r.index == nil {
+		return nil, errors.New("source of releases was not configured")
+	}
+	versions, err := r.index.versions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	releases := []Release{}
+	for _, v := range versions {
+		releases = append(releases, Release{tag: r.index.tagPrefix + strings.TrimPrefix(v, r.index.tagPrefix)})
+	}
+	return releases, nil
+}
+
+// notes returns no release notes because server other than GitHub doesn't serve them.
+func (r *ExternalAssetRepository) notes(_ context.Context, _ Release, _ Release) ([]ReleaseNote, error) {
+	return []ReleaseNote{}, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalAssetRepositoryFromSource(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /terraform/", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `<ul>
+<li><a href="/terraform/1.10.0-alpha20240619/">terraform_1.10.0-alpha20240619</a></li>
+<li><a href="/terraform/1.9.2/">terraform_1.9.2</a></li>
+<li><a href="/terraform/1.10.1/">terraform_1.10.1</a></li>
+</ul>`) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /v1/check/terraform", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"product":"terraform","current_version":"1.11.0"}`) // nolint:errcheck
+	})
+
+	tests := []struct {
+		name   string
+		source SourceConfig
+		latest Release
+	}{
+		{
+			name: "Index",
+			source: SourceConfig{
+				Type:           "http",
+				Index:          server.URL + "/terraform/",
+				VersionPattern: `terraform_([^<]+)<`,
+				TagPrefix:      "v",
+				Assets:         []string{server.URL + "/terraform/{{.SemVer}}/terraform_{{.SemVer}}_linux_amd64.zip"},
+			},
+			latest: Release{tag: "v1.10.1"},
+		},
+		{
+			name: "Latest",
+			source: SourceConfig{
+				Type:        "http",
+				Latest:      server.URL + "/v1/check/terraform",
+				LatestField: "current_version",
+				Assets:      []string{server.URL + "/terraform/{{.SemVer}}/terraform_{{.SemVer}}_linux_amd64.zip"},
+			},
+			latest: Release{tag: "1.11.0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctx := context.Background()
+
+			r, err := newExternalAssetRepositoryFromSource(tt.source, http.DefaultClient, io.Discard)
+			require.NoError(err)
+
+			latest, err := r.latest(ctx)
+			require.NoError(err)
+			require.Equal(tt.latest, latest)
+
+			assets, err := r.list(ctx, latest)
+			require.NoError(err)
+			require.Equal([]Asset{{downloadURL: must(url.Parse(fmt.Sprintf("%s/terraform/%s/terraform_%s_linux_amd64.zip", server.URL, latest.semVer(), latest.semVer())))}}, assets)
+		})
+	}
+}
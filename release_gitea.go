@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+)
+
+// GiteaReleaseRepository is a repository for [Release] of Gitea or Forgejo repository.
+type GiteaReleaseRepository struct {
+	client *giteaClient
+}
+
+// newGiteaReleaseRepository returns a new [GiteaReleaseRepository] object.
+func newGiteaReleaseRepository(repo Repository, host HostConfig) *GiteaReleaseRepository {
+	return &GiteaReleaseRepository{
+		client: newGiteaClient(repo, host),
+	}
+}
+
+// latest returns the latest Gitea release which is neither draft nor prerelease.
+func (r *GiteaReleaseRepository) latest(ctx context.Context) (Release, error) {
+	release, err := r.client.latestRelease(ctx)
+	if err != nil {
+		return Release{}, err
+	}
+	return Release{
+		tag: release.TagName,
+	}, nil
+}
+
+// releases returns Gitea releases which are neither draft nor prerelease, newest first.
+// Only releases in the first [maxReleasePages] pages are returned.
+func (r *GiteaReleaseRepository) releases(ctx context.Context) ([]Release, error) {
+	result := []Release{}
+	for page := 1; page <= maxReleasePages; page++ {
+		releases, err := r.client.releases(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			if !release.Draft && !release.Prerelease {
+				result = append(result, Release{tag: release.TagName})
+			}
+		}
+		if len(releases) == 0 {
+			break
+		}
+	}
+	return result, nil
+}
+
+// notes returns release notes of Gitea releases which are newer than from and not newer than to, newest first.
+// Prereleases other than to are skipped. If from is zero value, this returns release note of to only.
+func (r *GiteaReleaseRepository) notes(ctx context.Context, from Release, to Release) ([]ReleaseNote, error) {
+	if from.tag == "" {
+		release, err := r.client.release(ctx, to.tag)
+		if err != nil {
+			return nil, err
+		}
+		return []ReleaseNote{newGiteaReleaseNote(release)}, nil
+	}
+
+	notes := []ReleaseNote{}
+	for page := 1; page <= maxReleasePages; page++ {
+		releases, err := r.client.releases(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			note := newGiteaReleaseNote(release)
+			if note.release.tag == from.tag {
+				return notes, nil
+			}
+			if release.Draft || (release.Prerelease && note.release.tag != to.tag) {
+				continue
+			}
+			if inReleaseRange(note.release, from, to, len(notes) > 0) {
+				notes = append(notes, note)
+			}
+		}
+		if len(releases) == 0 {
+			break
+		}
+	}
+	return notes, nil
+}
+
+// newGiteaReleaseNote returns a new [ReleaseNote] object of given Gitea release.
+func newGiteaReleaseNote(release giteaRelease) ReleaseNote {
+	return ReleaseNote{
+		release: Release{
+			tag: release.TagName,
+		},
+		name: release.Name,
+		body: release.Body,
+	}
+}
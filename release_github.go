@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// GitHubReleaseRepository is a repository for [Release].
+type GitHubReleaseRepository struct {
+	client *github.Client
+	token  *GitHubToken
+	repo   Repository
+}
+
+// newGitHubReleaseRepository returns a new [GitHubReleaseRepository] object.
+func newGitHubReleaseRepository(repo Repository, host HostConfig) (*GitHubReleaseRepository, error) {
+	client, token, err := newGitHubClient(repo, host)
+	if err != nil {
+		return nil, err
+	}
+	return &GitHubReleaseRepository{
+		client: client,
+		token:  token,
+		repo:   repo,
+	}, nil
+}
+
+// latest returns the latest GitHub release which is neither draft nor prerelease.
+func (r *GitHubReleaseRepository) latest(ctx context.Context) (Release, error) {
+	release, _, err := r.client.Repositories.GetLatestRelease(ctx, r.repo.owner, r.repo.name)
+	if err != nil {
+		return Release{}, r.token.explain(err)
+	}
+	return Release{
+		tag: release.GetTagName(),
+	}, nil
+}
+
+// releases returns GitHub releases which are neither draft nor prerelease, newest first.
+// Only releases in the first [maxReleasePages] pages are returned.
+func (r *GitHubReleaseRepository) releases(ctx context.Context) ([]Release, error) {
+	result := []Release{}
+	for page := 1; page != 0 && page <= maxReleasePages; {
+		releases, resp, err := r.client.Repositories.ListReleases(ctx, r.repo.owner, r.repo.name, &github.ListOptions{
+			Page:    page,
+			PerPage: 100,
+		})
+		if err != nil {
+			return nil, r.token.explain(err)
+		}
+		for _, release := range releases {
+			if !release.GetDraft() && !release.GetPrerelease() {
+				result = append(result, Release{tag: release.GetTagName()})
+			}
+		}
+		page = resp.NextPage
+	}
+	return result, nil
+}
+
+// maxReleasePages is the maximum number of pages of GitHub releases to look up release notes in.
+const maxReleasePages = 10
+
+// notes returns release notes of GitHub releases which are newer than from and not newer than to, newest first.
+// Prereleases other than to are skipped. If from is zero value, this returns release note of to only.
+func (r *GitHubReleaseRepository) notes(ctx context.Context, from Release, to Release) ([]ReleaseNote, error) {
+	if from.tag == "" {
+		release, _, err := r.client.Repositories.GetReleaseByTag(ctx, r.repo.owner, r.repo.name, to.tag)
+		if err != nil {
+			return nil, r.token.explain(err)
+		}
+		return []ReleaseNote{newReleaseNote(release)}, nil
+	}
+
+	notes := []ReleaseNote{}
+	for page := 1; page != 0 && page <= maxReleasePages; {
+		releases, resp, err := r.client.Repositories.ListReleases(ctx, r.repo.owner, r.repo.name, &github.ListOptions{
+			Page:    page,
+			PerPage: 100,
+		})
+		if err != nil {
+			return nil, r.token.explain(err)
+		}
+		for _, release := range releases {
+			note := newReleaseNote(release)
+			if note.release.tag == from.tag {
+				return notes, nil
+			}
+			if release.GetDraft() || (release.GetPrerelease() && note.release.tag != to.tag) {
+				continue
+			}
+			if inReleaseRange(note.release, from, to, len(notes) > 0) {
+				notes = append(notes, note)
+			}
+		}
+		page = resp.NextPage
+	}
+	return notes, nil
+}
+
+// newReleaseNote returns a new [ReleaseNote] object of given GitHub release.
+func newReleaseNote(release *github.RepositoryRelease) ReleaseNote {
+	return ReleaseNote{
+		release: Release{
+			tag: release.GetTagName(),
+		},
+		name: release.GetName(),
+		body: release.GetBody(),
+	}
+}
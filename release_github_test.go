@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRelease is a GitHub release served by fake server.
+type fakeRelease struct {
+	tag        string
+	draft      bool
+	prerelease bool
+}
+
+// newFakeReleaseServer starts a fake GitHub API server which serves given releases of each repository, newest first.
+// Releases are listed in pages which have given number of releases regardless of requested page size.
+func newFakeReleaseServer(t *testing.T, repos map[string][]fakeRelease, perPage int) *httptest.Server {
+	t.Helper()
+	encode := func(r fakeRelease) map[string]any {
+		return map[string]any{"tag_name": r.tag, "name": r.tag, "body": "Release " + r.tag, "draft": r.draft, "prerelease": r.prerelease}
+	}
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("GET /repos/owner/{repo}/releases", func(w http.ResponseWriter, r *http.Request) {
+		releases := repos[r.PathValue("repo")]
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		start, end := min((page-1)*perPage, len(releases)), min(page*perPage, len(releases))
+		if end < len(releases) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%d>; rel="next"`, server.URL, r.URL.Path, page+1))
+		}
+		body := []map[string]any{}
+		for _, release := range releases[start:end] {
+			body = append(body, encode(release))
+		}
+		json.NewEncoder(w).Encode(body) // nolint:errcheck
+	})
+	mux.HandleFunc("GET /repos/owner/{repo}/releases/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		for _, release := range repos[r.PathValue("repo")] {
+			if release.tag == r.PathValue("tag") {
+				json.NewEncoder(w).Encode(encode(release)) // nolint:errcheck
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+	return server
+}
+
+// newFakeGitHubReleaseRepository returns [GitHubReleaseRepository] object for "owner/<name>" repository on given fake server.
+func newFakeGitHubReleaseRepository(t *testing.T, server *httptest.Server, name string) *GitHubReleaseRepository {
+	t.Helper()
+	client := github.NewClient(server.Client())
+	client.BaseURL = must(url.Parse(server.URL + "/"))
+	return &GitHubReleaseRepository{
+		client: client,
+		token:  &GitHubToken{},
+		repo:   Repository{host: "github.com", owner: "owner", name: name},
+	}
+}
+
+func TestGitHubReleaseRepositoryNotes(t *testing.T) {
+	many := []fakeRelease{}
+	for i := 3 * maxReleasePages; i >= 0; i-- {
+		many = append(many, fakeRelease{tag: fmt.Sprintf("v1.0.%d", i)})
+	}
+	server := newFakeReleaseServer(t, map[string][]fakeRelease{
+		"tool": {
+			{tag: "v2.0.0"},
+			{tag: "v2.0.0-rc.1", prerelease: true},
+			{tag: "v1.9.0", draft: true},
+			{tag: "v1.2.0"},
+			{tag: "v1.1.0"},
+			{tag: "v1.0.0"},
+		},
+		"nightly": {
+			{tag: "nightly-3"},
+			{tag: "nightly-2"},
+			{tag: "nightly-1"},
+		},
+		"many": many,
+	}, 1)
+
+	tests := []struct {
+		name string
+		repo string
+		from string
+		to   string
+		want []string
+	}{
+		{
+			name: "Range",
+			repo: "tool",
+			from: "v1.0.0",
+			to:   "v1.2.0",
+			want: []string{"v1.2.0", "v1.1.0"},
+		},
+		{
+			name: "SkipDraftAndPrerelease",
+			repo: "tool",
+			from: "v1.1.0",
+			to:   "v2.0.0",
+			want: []string{"v2.0.0", "v1.2.0"},
+		},
+		{
+			name: "PrereleaseAsTo",
+			repo: "tool",
+			from: "v1.2.0",
+			to:   "v2.0.0-rc.1",
+			want: []string{"v2.0.0-rc.1"},
+		},
+		{
+			name: "EmptyFrom",
+			repo: "tool",
+			from: "",
+			to:   "v1.1.0",
+			want: []string{"v1.1.0"},
+		},
+		{
+			name: "NotSemanticVersion",
+			repo: "nightly",
+			from: "nightly-1",
+			to:   "nightly-2",
+			want: []string{"nightly-2"},
+		},
+		{
+			name: "MaxPages",
+			repo: "many",
+			from: "v1.0.0",
+			to:   fmt.Sprintf("v1.0.%d", 3*maxReleasePages),
+			want: func() []string {
+				tags := []string{}
+				for _, r := range many[:maxReleasePages] {
+					tags = append(tags, r.tag)
+				}
+				return tags
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newFakeGitHubReleaseRepository(t, server, tt.repo)
+			notes, err := r.notes(context.Background(), Release{tag: tt.from}, Release{tag: tt.to})
+			require.NoError(t, err)
+			tags := []string{}
+			for _, note := range notes {
+				tags = append(tags, note.release.tag)
+			}
+			require.Equal(t, tt.want, tags)
+		})
+	}
+}
+
+func TestGitHubReleaseRepositoryReleases(t *testing.T) {
+	many := []fakeRelease{}
+	for i := 3 * maxReleasePages; i >= 0; i-- {
+		many = append(many, fakeRelease{tag: fmt.Sprintf("v1.0.%d", i), prerelease: i%2 == 1})
+	}
+	server := newFakeReleaseServer(t, map[string][]fakeRelease{"many": many}, 2)
+
+	releases, err := newFakeGitHubReleaseRepository(t, server, "many").releases(context.Background())
+	require.NoError(t, err)
+	require.Len(t, releases, maxReleasePages, "only releases in first pages which are not prerelease should be returned")
+	require.Equal(t, Release{tag: fmt.Sprintf("v1.0.%d", 3*maxReleasePages)}, releases[0])
+}
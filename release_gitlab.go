@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// GitLabReleaseRepository is a repository for [Release] of GitLab project.
+type GitLabReleaseRepository struct {
+	client *gitLabClient
+}
+
+// newGitLabReleaseRepository returns a new [GitLabReleaseRepository] object.
+func newGitLabReleaseRepository(repo Repository, host HostConfig) *GitLabReleaseRepository {
+	return &GitLabReleaseRepository{
+		client: newGitLabClient(repo, host),
+	}
+}
+
+// latest returns the latest GitLab release which is not upcoming release.
+func (r *GitLabReleaseRepository) latest(ctx context.Context) (Release, error) {
+	releases, err := r.client.releases(ctx, 1)
+	if err != nil {
+		return Release{}, err
+	}
+	for _, release := range releases {
+		if !release.UpcomingRelease {
+			return Release{
+				tag: release.TagName,
+			}, nil
+		}
+	}
+	return Release{}, errors.New("no releases were found")
+}
+
+// releases returns GitLab releases which are not upcoming release, newest first.
+// Only releases in the first [maxReleasePages] pages are returned.
+func (r *GitLabReleaseRepository) releases(ctx context.Context) ([]Release, error) {
+	result := []Release{}
+	for page := 1; page <= maxReleasePages; page++ {
+		releases, err := r.client.releases(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			if !release.UpcomingRelease {
+				result = append(result, Release{tag: release.TagName})
+			}
+		}
+		if len(releases) == 0 {
+			break
+		}
+	}
+	return result, nil
+}
+
+// notes returns release notes of GitLab releases which are newer than from and not newer than to, newest first.
+// If from is zero value, this returns release note of to only.
+func (r *GitLabReleaseRepository) notes(ctx context.Context, from Release, to Release) ([]ReleaseNote, error) {
+	if from.tag == "" {
+		release, err := r.client.release(ctx, to.tag)
+		if err != nil {
+			return nil, err
+		}
+		return []ReleaseNote{newGitLabReleaseNote(release)}, nil
+	}
+
+	notes := []ReleaseNote{}
+	for page := 1; page <= maxReleasePages; page++ {
+		releases, err := r.client.releases(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		for _, release := range releases {
+			note := newGitLabReleaseNote(release)
+			if note.release.tag == from.tag {
+				return notes, nil
+			}
+			if inReleaseRange(note.release, from, to, len(notes) > 0) && !release.UpcomingRelease {
+				notes = append(notes, note)
+			}
+		}
+		if len(releases) == 0 {
+			break
+		}
+	}
+	return notes, nil
+}
+
+// newGitLabReleaseNote returns a new [ReleaseNote] object of given GitLab release.
+func newGitLabReleaseNote(release gitLabRelease) ReleaseNote {
+	return ReleaseNote{
+		release: Release{
+			tag: release.TagName,
+		},
+		name: release.Name,
+		body: release.Description,
+	}
+}
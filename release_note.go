@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	// breakingChangePattern matches text mentioning breaking changes.
+	breakingChangePattern = regexp.MustCompile(`(?i)\bbreaking\b`)
+
+	// markdownCommentPattern matches HTML comments in markdown.
+	markdownCommentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+	// markdownHeadingPattern matches ATX heading line in markdown.
+	markdownHeadingPattern = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+
+	// markdownListPattern matches bullet list item line in markdown.
+	markdownListPattern = regexp.MustCompile(`^(\s*)[\*\-\+]\s+(.*)$`)
+
+	// markdownLinkPattern matches inline link in markdown.
+	markdownLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\(([^\)]*)\)`)
+
+	// markdownEmphasisPattern matches strong emphasis in markdown.
+	markdownEmphasisPattern = regexp.MustCompile(`(\*\*|__)(.+?)(\*\*|__)`)
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+	ansiRed   = "\x1b[1;31m"
+)
+
+// ReleaseNote represents a release note of a GitHub release.
+type ReleaseNote struct {
+	release Release
+
+	// name is a title of GitHub release.
+	name string
+
+	// body is a description of GitHub release written in markdown.
+	body string
+}
+
+// breaking returns true if release note mentions breaking changes.
+func (n ReleaseNote) breaking() bool {
+	return breakingChangePattern.MatchString(n.name) || breakingChangePattern.MatchString(n.body)
+}
+
+// render writes release note rendered for terminal into w.
+// Lines mentioning breaking changes are highlighted. If color is false, they are marked by "!" instead of colors.
+func (n ReleaseNote) render(w io.Writer, color bool) error {
+	style := func(s string, code string) string {
+		if !color {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	title := n.release.tag
+	if n.name != "" && n.name != n.release.tag {
+		title = fmt.Sprintf("%s (%s)", n.release.tag, n.name)
+	}
+	if n.breaking() {
+		title = style(title+" - BREAKING CHANGES", ansiRed)
+	} else {
+		title = style(title, ansiBold)
+	}
+	if _, err := fmt.Fprintf(w, "== %s\n", title); err != nil {
+		return err
+	}
+
+	body := markdownCommentPattern.ReplaceAllString(strings.ReplaceAll(n.body, "\r\n", "\n"), "")
+	for _, line := range strings.Split(strings.TrimSpace(body), "\n") {
+		line = markdownLinkPattern.ReplaceAllString(line, "$1 ($2)")
+		switch {
+		case markdownHeadingPattern.MatchString(line):
+			line = markdownHeadingPattern.ReplaceAllString(line, "$1")
+			line = markdownEmphasisPattern.ReplaceAllString(line, "$2")
+			line = style(line, ansiBold)
+		case markdownListPattern.MatchString(line):
+			line = markdownListPattern.ReplaceAllString(line, "$1• $2")
+			fallthrough
+		default:
+			line = markdownEmphasisPattern.ReplaceAllStringFunc(line, func(s string) string {
+				return style(markdownEmphasisPattern.ReplaceAllString(s, "$2"), ansiBold)
+			})
+		}
+		if breakingChangePattern.MatchString(line) {
+			if color {
+				line = ansiRed + line + ansiReset
+			} else {
+				line = "! " + line
+			}
+		}
+		if line != "" {
+			line = "   " + line
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// renderReleaseNotes writes given release notes rendered for terminal into w.
+func renderReleaseNotes(w io.Writer, notes []ReleaseNote, color bool) error {
+	for _, n := range notes {
+		if err := n.render(w, color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inReleaseRange returns true if release note of given release should be shown when upgrading from "from" to "to".
+// Releases are compared as semantic version if all of them have it.
+// Otherwise, release is included only if release "to" was already found while listing releases newest first.
+func inReleaseRange(release Release, from Release, to Release, found bool) bool {
+	if release.tag == to.tag {
+		return true
+	}
+	if release.semVer() != "" && from.semVer() != "" && to.semVer() != "" {
+		return to.newerThan(release) && release.newerThan(from)
+	}
+	return found
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseNoteRender(t *testing.T) {
+	require := require.New(t)
+
+	note := ReleaseNote{
+		release: Release{tag: "v3.16.0"},
+		name:    "Helm v3.16.0",
+		body:    "<!-- generated -->\r\n## Notable Changes\r\n\r\n* **Breaking**: removed `--foo` flag\r\n* Added [docs](https://helm.sh/docs)\r\n",
+	}
+	require.True(note.breaking())
+
+	var b bytes.Buffer
+	require.NoError(note.render(&b, false))
+	require.Equal(`== v3.16.0 (Helm v3.16.0) - BREAKING CHANGES
+   Notable Changes
+
+   ! • Breaking: removed `+"`--foo`"+` flag
+   • Added docs (https://helm.sh/docs)
+
+`, b.String())
+}
+
+func TestInReleaseRange(t *testing.T) {
+	tests := []struct {
+		release string
+		from    string
+		to      string
+		found   bool
+		want    bool
+	}{
+		{release: "v1.2.0", from: "v1.0.0", to: "v1.2.0", want: true},
+		{release: "v1.1.0", from: "v1.0.0", to: "v1.2.0", want: true},
+		{release: "v1.3.0", from: "v1.0.0", to: "v1.2.0", want: false},
+		{release: "v1.0.0", from: "v1.0.0", to: "v1.2.0", want: false},
+		{release: "v0.9.0", from: "v1.0.0", to: "v1.2.0", want: false},
+		{release: "v1.1.0", from: "v1.0.0", to: "v1.2.0", found: true, want: true},
+		{release: "nightly-2", from: "nightly-1", to: "nightly-3", found: false, want: false},
+		{release: "nightly-2", from: "nightly-1", to: "nightly-3", found: true, want: true},
+		{release: "nightly-3", from: "nightly-1", to: "nightly-3", found: false, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.release+"/"+tt.from+".."+tt.to, func(t *testing.T) {
+			require.Equal(t, tt.want, inReleaseRange(Release{tag: tt.release}, Release{tag: tt.from}, Release{tag: tt.to}, tt.found))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// OCIReleaseRepository is a repository for [Release] of repository in OCI registry. Each tag is regarded as a release.
+type OCIReleaseRepository struct {
+	client *ociClient
+}
+
+// newOCIReleaseRepository returns a new [OCIReleaseRepository] object.
+func newOCIReleaseRepository(repo Repository, host HostConfig) *OCIReleaseRepository {
+	return &OCIReleaseRepository{
+		client: newOCIClient(repo, host),
+	}
+}
+
+// latest returns tag which is the newest stable semantic version in repository.
+func (r *OCIReleaseRepository) latest(ctx context.Context) (Release, error) {
+	tags, err := r.client.tags(ctx)
+	if err != nil {
+		return Release{}, err
+	}
+	latest := Release{}
+	for _, tag := range tags {
+		release := Release{tag: tag}
+		if release.semVer() == "" || release.prerelease() {
+			continue
+		}
+		if latest.tag == "" || release.newerThan(latest) {
+			latest = release
+		}
+	}
+	if latest.tag == "" {
+		return Release{}, errors.New("no tags of semantic version were found")
+	}
+	return latest, nil
+}
+
+// releases returns all tags in repository as releases.
+func (r *OCIReleaseRepository) releases(ctx context.Context) ([]Release, error) {
+	tags, err := r.client.tags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	releases := []Release{}
+	for _, tag := range tags {
+		releases = append(releases, Release{tag: tag})
+	}
+	return releases, nil
+}
+
+// notes returns no release notes because OCI registry doesn't serve them.
+func (r *OCIReleaseRepository) notes(_ context.Context, _ Release, _ Release) ([]ReleaseNote, error) {
+	return []ReleaseNote{}, nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseNewerThan(t *testing.T) {
+	tests := []struct {
+		release Release
+		other   Release
+		newer   bool
+	}{
+		{release: Release{tag: "v1.10.0"}, other: Release{tag: "v1.9.0"}, newer: true},
+		{release: Release{tag: "v1.9.0"}, other: Release{tag: "v1.10.0"}, newer: false},
+		{release: Release{tag: "v1.9.0"}, other: Release{tag: "v1.9.0"}, newer: false},
+		{release: Release{tag: "0.8.1"}, other: Release{tag: "0.8.0"}, newer: true},
+		{release: Release{tag: "nightly-2"}, other: Release{tag: "nightly-1"}, newer: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.release.tag+"/"+tt.other.tag, func(t *testing.T) {
+			require.Equal(t, tt.newer, tt.release.newerThan(tt.other))
+		})
+	}
+}
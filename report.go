@@ -0,0 +1,191 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Statuses of [InstallReport].
+const (
+	statusInstalled = "installed"
+	statusUpToDate  = "up_to_date"
+	statusCancelled = "cancelled"
+	statusPlanned   = "planned"
+	statusFailed    = "failed"
+)
+
+// InstallReport is a machine-readable record of finding and installing an executable binary.
+type InstallReport struct {
+	// Repo is a repository name which executable binary is installed from.
+	Repo string `json:"repo" yaml:"repo"`
+
+	// Tag is a resolved release tag. "latest" is resolved into actual tag.
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+
+	// Asset is a release asset which executable binary is extracted from. This is nil if release asset was not found.
+	Asset *AssetReport `json:"asset,omitempty" yaml:"asset,omitempty"`
+
+	// Name is a name of executable binary in release asset.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// InstallName is a name which executable binary is installed as.
+	InstallName string `json:"installName,omitempty" yaml:"installName,omitempty"`
+
+	// Path is an absolute path where executable binary is installed.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+
+	// Digest is a digest of executable binary content in "sha256:HEX" format.
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty"`
+
+	// Size is a size of executable binary in bytes.
+	Size int `json:"size,omitempty" yaml:"size,omitempty"`
+
+	// Format is a result of inspecting header of executable binary, such as "ELF 64-bit linux/amd64".
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// Status is one of "installed", "up_to_date", "cancelled", "planned" and "failed".
+	// "planned" means executable binary would be installed but nothing was written because of dry run.
+	Status string `json:"status" yaml:"status"`
+
+	// Verified is true if installed executable binary was verified by running it.
+	Verified bool `json:"verified" yaml:"verified"`
+
+	// Warnings are messages which didn't prevent installation, such as platform mismatch which was explicitly allowed.
+	Warnings []string `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+
+	// StartedAt is a time when installation started.
+	StartedAt time.Time `json:"startedAt" yaml:"startedAt"`
+
+	// DurationSeconds is a duration of installation in seconds.
+	DurationSeconds float64 `json:"durationSeconds" yaml:"durationSeconds"`
+
+	// TreeRoot is an absolute path of directory which whole archive is extracted into with --tree.
+	TreeRoot string `json:"treeRoot,omitempty" yaml:"treeRoot,omitempty"`
+
+	// Companions are absolute paths of companion files such as man pages and shell completions installed with executable binary.
+	Companions []string `json:"companions,omitempty" yaml:"companions,omitempty"`
+
+	// Replaces is an executable binary which already exists at Path and would be replaced. This is set only in dry run.
+	Replaces *ReplacedReport `json:"replaces,omitempty" yaml:"replaces,omitempty"`
+
+	// Error is an error which caused installation to fail. This is nil if installation didn't fail.
+	Error *ErrorReport `json:"error,omitempty" yaml:"error,omitempty"`
+
+	// dir is a directory given by user where executable binary is installed into. This is shown in human-readable output.
+	dir string
+
+	// verifyRun is a command which verified executable binary. This is shown in human-readable output.
+	verifyRun string
+}
+
+// AssetReport is a machine-readable record of release asset.
+type AssetReport struct {
+	ID   int64  `json:"id" yaml:"id"`
+	URL  string `json:"url" yaml:"url"`
+	Size int64  `json:"size,omitempty" yaml:"size,omitempty"`
+}
+
+// ReplacedReport is a machine-readable record of executable binary which would be replaced.
+// Repo and Tag are set if it was installed by this tool, and Version is set if version probe command tells its version.
+type ReplacedReport struct {
+	Repo    string `json:"repo,omitempty" yaml:"repo,omitempty"`
+	Tag     string `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+	Digest  string `json:"digest" yaml:"digest"`
+}
+
+// ErrorReport is a machine-readable record of error.
+type ErrorReport struct {
+	Code    ErrorCode `json:"code" yaml:"code"`
+	Message string    `json:"message" yaml:"message"`
+}
+
+// newInstallReport returns a new [InstallReport] object for installation starting now.
+func newInstallReport(repo string, dir string) *InstallReport {
+	return &InstallReport{
+		Repo:      repo,
+		StartedAt: time.Now().UTC(),
+		dir:       dir,
+	}
+}
+
+// finish records duration of installation and error which caused it to fail.
+func (r *InstallReport) finish(err error) {
+	r.DurationSeconds = time.Since(r.StartedAt).Seconds()
+	if err != nil {
+		r.Status = statusFailed
+		r.Error = &ErrorReport{
+			Code:    errorCode(err),
+			Message: err.Error(),
+		}
+	}
+}
+
+// printText writes human-readable result of installation. Warnings are written into stderr.
+func (r *InstallReport) printText(stdout io.Writer, stderr io.Writer) {
+	switch r.Status {
+	case statusUpToDate:
+		fmt.Fprintf(stdout, "%s is already up to date (%s)\n", r.InstallName, r.Tag) // nolint:errcheck
+	case statusInstalled:
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(stderr, "warning: %s\n", warning) // nolint:errcheck
+		}
+		fmt.Fprintf(stdout, "Installed %s (%s) into %s\n", r.InstallName, r.Format, r.dir) // nolint:errcheck
+		if r.Verified {
+			fmt.Fprintf(stdout, "Verified %s by running %q\n", r.InstallName, r.verifyRun) // nolint:errcheck
+		}
+		for _, companion := range r.Companions {
+			fmt.Fprintf(stdout, "Installed %s\n", companion) // nolint:errcheck
+		}
+	case statusPlanned:
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(stderr, "warning: %s\n", warning) // nolint:errcheck
+		}
+		fmt.Fprintf(stdout, "Would install %s (%s) from %s into %s\n", r.InstallName, r.Tag, r.Asset.URL, r.Path) // nolint:errcheck
+		switch {
+		case r.Replaces == nil:
+		case r.Replaces.Tag != "":
+			fmt.Fprintf(stdout, "Would replace %s (%s) installed from %s\n", r.InstallName, r.Replaces.Tag, r.Replaces.Repo) // nolint:errcheck
+		case r.Replaces.Version != "":
+			fmt.Fprintf(stdout, "Would replace %s which reports version %q\n", r.InstallName, r.Replaces.Version) // nolint:errcheck
+		default:
+			fmt.Fprintf(stdout, "Would replace %s of unknown version\n", r.InstallName) // nolint:errcheck
+		}
+		if r.TreeRoot != "" {
+			fmt.Fprintf(stdout, "Would extract whole archive into %s\n", r.TreeRoot) // nolint:errcheck
+		}
+		if r.Format != "" {
+			fmt.Fprintf(stdout, "Extracted %s (%s, %d bytes) from release asset successfully\n", r.Name, r.Format, r.Size) // nolint:errcheck
+		}
+		for _, companion := range r.Companions {
+			fmt.Fprintf(stdout, "Would install %s\n", companion) // nolint:errcheck
+		}
+		fmt.Fprintln(stdout, "Nothing was written because of --dry-run") // nolint:errcheck
+	}
+}
+
+// outputFormats are formats of machine-readable output.
+var outputFormats = []string{"json", "yaml"}
+
+// writeReport writes given report in given machine-readable format.
+func writeReport(w io.Writer, format string, v any) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("output format was unknown: %s", format)
+	}
+}
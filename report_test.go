@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteReport(t *testing.T) {
+	report := &InstallReport{
+		Repo:        "cli/cli",
+		Tag:         "v2.52.0",
+		Asset:       &AssetReport{ID: 1, URL: "https://github.com/cli/cli/releases/download/v2.52.0/gh_2.52.0_linux_amd64.tar.gz", Size: 100},
+		Name:        "gh",
+		InstallName: "gh",
+		Status:      statusInstalled,
+		StartedAt:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	failed := &InstallReport{
+		Repo:      "cli/cli",
+		StartedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	failed.finish(withCode(ErrNoMatchingAsset, errors.New("no release asset matched")))
+	failed.DurationSeconds = 0
+
+	tests := []struct {
+		name   string
+		format string
+		report *InstallReport
+		output string
+	}{
+		{
+			name:   "JSON",
+			format: "json",
+			report: report,
+			output: `{
+  "repo": "cli/cli",
+  "tag": "v2.52.0",
+  "asset": {
+    "id": 1,
+    "url": "https://github.com/cli/cli/releases/download/v2.52.0/gh_2.52.0_linux_amd64.tar.gz",
+    "size": 100
+  },
+  "name": "gh",
+  "installName": "gh",
+  "status": "installed",
+  "verified": false,
+  "startedAt": "2024-01-01T00:00:00Z",
+  "durationSeconds": 0
+}
+`,
+		},
+		{
+			name:   "YAML",
+			format: "yaml",
+			report: failed,
+			output: `repo: cli/cli
+status: failed
+verified: false
+startedAt: 2024-01-01T00:00:00Z
+durationSeconds: 0
+error:
+  code: no_matching_asset
+  message: no release asset matched
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			require.NoError(t, writeReport(&b, tt.format, tt.report))
+			require.Equal(t, tt.output, b.String())
+		})
+	}
+}
@@ -1,21 +1,46 @@
 package main
 
 import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
 	"github.com/cli/go-gh/v2/pkg/repository"
 )
 
 // Repository represents a GitHub repository.
+// This also represents a repository in OCI registry if scheme is "oci" and a project in GitLab subgroup. In those cases, owner may contain slashes.
 type Repository struct {
-	host  string
-	name  string
-	owner string
+	scheme string
+	host   string
+	name   string
+	owner  string
+}
+
+// String returns repository name in HOST/OWNER/REPO format.
+// Repository in OCI registry is returned in oci://REGISTRY/NAMESPACE/REPO format.
+func (r Repository) String() string {
+	if r.scheme != "" {
+		return fmt.Sprintf("%s://%s/%s/%s", r.scheme, r.host, r.owner, r.name)
+	}
+	return fmt.Sprintf("%s/%s/%s", r.host, r.owner, r.name)
 }
 
 // parseRepository extracts the repository information from the following string formats: "OWNER/REPO", "HOST/OWNER/REPO", and a full URL.
 // If the format does not specify a host, use the config to determine a host.
+// Reference to repository in OCI registry such as "oci://ghcr.io/fluxcd/flux-cli:v2.3.0" is also accepted and its tag is ignored.
+// Project in GitLab subgroup such as "gitlab.com/group/subgroup/project" is also accepted if host is given.
 func parseRepository(s string) (Repository, error) {
+	if strings.HasPrefix(s, "oci://") {
+		repo, _, err := parseOCIReference(s)
+		return repo, err
+	}
 	repo, err := repository.Parse(s)
 	if err != nil {
+		if nested, ok := parseNestedRepository(s); ok {
+			return nested, nil
+		}
 		return Repository{}, err
 	}
 	return Repository{
@@ -25,6 +50,60 @@ func parseRepository(s string) (Repository, error) {
 	}, nil
 }
 
+// parseNestedRepository extracts repository whose owner is nested namespace from string in HOST/GROUP/SUBGROUP.../REPO format or a full URL.
+// This returns false if string doesn't have nested namespace.
+func parseNestedRepository(s string) (Repository, bool) {
+	if u, err := url.Parse(s); err == nil && (u.Scheme == "https" || u.Scheme == "http") {
+		s = u.Host + u.Path
+	}
+	segments := strings.Split(strings.TrimSuffix(strings.Trim(s, "/"), ".git"), "/")
+	if len(segments) < 4 || slices.Contains(segments, "") {
+		return Repository{}, false
+	}
+	return Repository{
+		host:  segments[0],
+		owner: strings.Join(segments[1:len(segments)-1], "/"),
+		name:  segments[len(segments)-1],
+	}, true
+}
+
+// checkNamespace returns error if owner of repository is nested namespace but type of host doesn't support it.
+func checkNamespace(repo Repository, host HostConfig) error {
+	if strings.Contains(repo.owner, "/") && host.Type != "gitlab" {
+		return fmt.Errorf("nested namespace is supported only by GitLab host, but type of host %s is %s: %s", repo.host, host.Type, repo)
+	}
+	return nil
+}
+
+// parseOCIReference extracts repository in OCI registry and tag from reference in oci://REGISTRY/NAMESPACE/REPO[:TAG|@DIGEST] format.
+// If reference has digest, this returns it as tag. If reference doesn't have tag, this returns empty tag.
+func parseOCIReference(s string) (Repository, string, error) {
+	ref, ok := strings.CutPrefix(s, "oci://")
+	if !ok {
+		return Repository{}, "", fmt.Errorf("OCI reference must start with oci://: %s", s)
+	}
+	host, path, ok := strings.Cut(ref, "/")
+	if !ok || host == "" || path == "" {
+		return Repository{}, "", fmt.Errorf("OCI reference must be oci://REGISTRY/NAMESPACE/REPO[:TAG] format: %s", s)
+	}
+	tag := ""
+	if i := strings.Index(path, "@"); i >= 0 {
+		path, tag = path[:i], path[i+1:]
+	} else if i := strings.LastIndex(path, ":"); i > strings.LastIndex(path, "/") {
+		path, tag = path[:i], path[i+1:]
+	}
+	i := strings.LastIndex(path, "/")
+	if i <= 0 || i == len(path)-1 {
+		return Repository{}, "", fmt.Errorf("OCI reference must be oci://REGISTRY/NAMESPACE/REPO[:TAG] format: %s", s)
+	}
+	return Repository{
+		scheme: "oci",
+		host:   host,
+		owner:  path[:i],
+		name:   path[i+1:],
+	}, tag, nil
+}
+
 // currentRepository returns the GitHub repository the current directory is tracking.
 func currentRepository() (Repository, error) {
 	repo, err := repository.Current()
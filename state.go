@@ -0,0 +1,132 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"time"
+)
+
+// InstallRecord is a record of executable binary installed by this tool.
+type InstallRecord struct {
+	// Repo is a GitHub repository name in HOST/OWNER/REPO format which executable binary was installed from.
+	Repo string `json:"repo"`
+
+	// Tag is a GitHub release tag which executable binary was installed from.
+	Tag string `json:"tag"`
+
+	// AssetURL is a download URL of GitHub release asset which executable binary was extracted from.
+	AssetURL string `json:"assetURL"`
+
+	// AssetURLs are download URLs of GitHub release assets of each version kept in store, keyed by release tag.
+	// This is set only for executable binary installed with --versioned, so that AssetURL can be updated when active version is switched.
+	AssetURLs map[string]string `json:"assetURLs,omitempty"`
+
+	// WorkflowRun is an ID of GitHub Actions workflow run whose artifact executable binary was installed from.
+	// Tag is pseudo tag of workflow run in this case, so such executable binary is never upgraded to release. This is zero if executable binary was installed from release.
+	WorkflowRun int64 `json:"workflowRun,omitempty"`
+
+	// Name is a name of executable binary in GitHub release asset.
+	Name string `json:"name"`
+
+	// Patterns are patterns which were used to select GitHub release asset. These are reused when executable binary is upgraded.
+	Patterns map[string]string `json:"patterns,omitempty"`
+
+	// NameTemplate is a template of executable binary name which executable binary was installed as. This is reused when executable binary is upgraded.
+	NameTemplate string `json:"nameTemplate,omitempty"`
+
+	// Path is an absolute path where executable binary was installed.
+	Path string `json:"path"`
+
+	// Digest is a digest of installed executable binary content in "sha256:HEX" format.
+	Digest string `json:"digest"`
+
+	// Companions are records of companion files such as man pages and shell completions installed with executable binary.
+	Companions []CompanionRecord `json:"companions,omitempty"`
+
+	// ShareDir is a share directory which companion files were installed into. This is reused when executable binary is upgraded.
+	ShareDir string `json:"shareDir,omitempty"`
+
+	// Tree is a record of archive tree which was installed and which Path links into. This is nil if only executable binary was installed.
+	Tree *TreeRecord `json:"tree,omitempty"`
+
+	// InstalledAt is a time when executable binary was installed.
+	InstalledAt time.Time `json:"installedAt"`
+}
+
+// installName returns a name of installed executable binary.
+func (r InstallRecord) installName() string {
+	return filepath.Base(r.Path)
+}
+
+// digest returns a digest of given content in "sha256:HEX" format.
+func digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// StateRepository is an interface about repository for [InstallRecord].
+type StateRepository interface {
+	list() ([]InstallRecord, error)
+	save(record InstallRecord) error
+	remove(record InstallRecord) error
+}
+
+// newStateRepository returns a new [StateRepository] object which stores [InstallRecord] into given file.
+func newStateRepository(path string) StateRepository {
+	return newFSStateRepository(path)
+}
+
+// defaultStatePath returns a default path of file which [InstallRecord] are stored into.
+func defaultStatePath() string {
+	return filepath.Join(xdgStateHome(), "gh-release-install", "state.json")
+}
+
+// findInstallRecords returns [InstallRecord] objects of executable binary which has given name.
+// If dir is not empty, this returns only [InstallRecord] objects of executable binary installed into it.
+func findInstallRecords(state StateRepository, name string, dir string) ([]InstallRecord, error) {
+	records, err := state.list()
+	if err != nil {
+		return nil, err
+	}
+	found := []InstallRecord{}
+	for _, r := range records {
+		if r.installName() != name {
+			continue
+		}
+		if dir != "" {
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				return nil, err
+			}
+			if filepath.Dir(r.Path) != abs {
+				continue
+			}
+		}
+		found = append(found, r)
+	}
+	return found, nil
+}
+
+// saveActiveVersion updates [InstallRecord] of executable binary installed with --versioned at given path after its active version was switched to given release.
+// Nothing is saved if executable binary at given path was not recorded.
+func saveActiveVersion(state StateRepository, path string, release Release, content ExecBinaryContent) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	records, err := state.list()
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if r.Path != abs {
+			continue
+		}
+		r.Tag = release.tag
+		r.Digest = digest(content)
+		r.AssetURL = r.AssetURLs[release.tag]
+		return state.save(r)
+	}
+	return nil
+}
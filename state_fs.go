@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// FSStateRepository is a repository for [InstallRecord] which stores them into a JSON file.
+type FSStateRepository struct {
+	path string
+}
+
+// newFSStateRepository returns a new [FSStateRepository] object.
+func newFSStateRepository(path string) *FSStateRepository {
+	return &FSStateRepository{
+		path: path,
+	}
+}
+
+// list returns all [InstallRecord] objects.
+func (r *FSStateRepository) list() ([]InstallRecord, error) {
+	b, err := os.ReadFile(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []InstallRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	records := []InstallRecord{}
+	if err := json.Unmarshal(b, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// save saves [InstallRecord]. Existing record of executable binary installed into same path is replaced.
+func (r *FSStateRepository) save(record InstallRecord) error {
+	return r.update(func(records []InstallRecord) []InstallRecord {
+		records = slices.DeleteFunc(records, func(e InstallRecord) bool {
+			return e.Path == record.Path
+		})
+		return append(records, record)
+	})
+}
+
+// remove removes [InstallRecord] of executable binary installed into same path as given one.
+func (r *FSStateRepository) remove(record InstallRecord) error {
+	return r.update(func(records []InstallRecord) []InstallRecord {
+		return slices.DeleteFunc(records, func(e InstallRecord) bool {
+			return e.Path == record.Path
+		})
+	})
+}
+
+// update reads all [InstallRecord] objects, applies given function to them and writes result under lock.
+func (r *FSStateRepository) update(f func([]InstallRecord) []InstallRecord) error {
+	unlock, err := lockDir(filepath.Dir(r.path))
+	if err != nil {
+		return err
+	}
+	defer unlock() // nolint:errcheck
+
+	records, err := r.list()
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(f(records), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(r.path, b, 0644)
+}
@@ -0,0 +1,691 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// TreeOptions are options to install whole archive tree instead of single executable binary.
+type TreeOptions struct {
+	// prefix is a directory under which archive is extracted into "opt/<owner>/<repo>/<tag>".
+	prefix string
+
+	// strip is a number of leading path components removed from file names in archive, like "tar --strip-components".
+	strip int
+
+	// links are paths of additional executable binaries in tree, relative to its root, which are linked into directory.
+	links []string
+}
+
+// treeRoot returns a directory where archive of given release of given repository is extracted into.
+// Owner is included so that repositories which have same name don't share tree, and each of owner, repo and tag is made single path segment by [pathSegment].
+func (o TreeOptions) treeRoot(repo Repository, release Release) string {
+	return filepath.Join(o.prefix, "opt", pathSegment(repo.owner), pathSegment(repo.name), pathSegment(release.tag))
+}
+
+// TreeRecord is a record of archive tree installed by this tool.
+type TreeRecord struct {
+	// Root is an absolute path of directory which archive was extracted into.
+	Root string `json:"root"`
+
+	// Prefix is a prefix which archive was extracted under. This is reused when executable binary is upgraded.
+	Prefix string `json:"prefix"`
+
+	// StripComponents is a number of leading path components removed from file names in archive. This is reused when executable binary is upgraded.
+	StripComponents int `json:"stripComponents,omitempty"`
+
+	// Links are paths of additional executable binaries in tree which were linked into directory, relative to root.
+	Links []string `json:"links,omitempty"`
+}
+
+// options returns [TreeOptions] to install archive tree in same way as this record.
+func (r TreeRecord) options() *TreeOptions {
+	return &TreeOptions{prefix: r.Prefix, strip: r.StripComponents, links: r.Links}
+}
+
+// linkPaths returns paths of symbolic links to additional executable binaries in given directory.
+func (r TreeRecord) linkPaths(dir string) []string {
+	paths := []string{}
+	for _, l := range r.Links {
+		paths = append(paths, filepath.Join(dir, path.Base(filepath.ToSlash(l))))
+	}
+	return paths
+}
+
+// defaultPrefix returns a prefix of tree install based on directory where executable binary is linked into.
+// If directory is "<prefix>/bin", this returns "<prefix>" following FHS. Otherwise, this returns parent of XDG data directory such as "$HOME/.local".
+func defaultPrefix(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err == nil && filepath.Base(abs) == "bin" {
+		return filepath.Dir(abs)
+	}
+	return filepath.Dir(xdgDataHome())
+}
+
+// extractTree extracts all files in archive into given directory, removing strip leading path components from their names.
+// Entries with absolute path or path escaping directory, entries written through symbolic links, and links pointing outside directory are rejected.
+// Decompressed archive and each entry in zip file are extracted within given limits.
+func (a AssetContent) extractTree(ctx context.Context, dir string, strip int, limits ExtractLimits) error {
+	limits = limits.orDefault()
+	ctx, cancel := limits.withTimeout(ctx)
+	defer cancel()
+	b, err := decompress(ctx, a, limits)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	root, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return err
+	}
+	t := &treeWriter{ctx: ctx, root: root, strip: strip, limits: limits}
+
+	switch mime := mimetype.Detect(b).String(); mime {
+	case "application/x-tar":
+		err = t.extractTar(bytes.NewReader(b))
+	case "application/zip":
+		err = t.extractZip(bytes.NewReader(b), int64(len(b)))
+	default:
+		err = withCode(ErrUnsupportedArchive, fmt.Errorf("MIME type of asset content was not archive: %s", mime))
+	}
+	if err != nil {
+		return err
+	}
+	return t.checkSymlinks()
+}
+
+// treeWriter writes entries of archive under root directory safely.
+type treeWriter struct {
+	ctx      context.Context
+	limits   ExtractLimits
+	root     string
+	strip    int
+	symlinks []string // paths of symbolic links created, which are checked after all entries are written.
+}
+
+// extractTar writes all entries in tarball.
+func (t *treeWriter) extractTar(r io.Reader) error {
+	for tr := tar.NewReader(r); ; {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		p, ok, err := t.path(header.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = t.mkdir(p)
+		case tar.TypeReg:
+			err = t.writeFile(p, t.limits.reader(t.ctx, tr, header.Size), fs.FileMode(header.Mode).Perm())
+		case tar.TypeSymlink:
+			err = t.symlink(p, header.Linkname)
+		case tar.TypeLink:
+			err = t.link(p, header.Linkname)
+		default:
+			// Devices, FIFOs and other special files are never needed to run tools.
+		}
+		if err != nil {
+			return fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+	}
+}
+
+// extractZip writes all entries in zip file.
+func (t *treeWriter) extractZip(r io.ReaderAt, size int64) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		p, ok, err := t.path(f.Name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := t.extractZipFile(p, f); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// extractZipFile writes an entry in zip file into given path.
+func (t *treeWriter) extractZipFile(p string, f *zip.File) error {
+	mode := f.Mode()
+	if !mode.IsDir() && !mode.IsRegular() && mode&fs.ModeSymlink == 0 {
+		return nil
+	}
+	if mode.IsDir() {
+		return t.mkdir(p)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close() // nolint:errcheck
+	if mode&fs.ModeSymlink != 0 {
+		target, err := io.ReadAll(io.LimitReader(rc, 4096))
+		if err != nil {
+			return err
+		}
+		return t.symlink(p, string(target))
+	}
+	return t.writeFile(p, t.limits.reader(t.ctx, rc, int64(f.CompressedSize64)), mode.Perm())
+}
+
+// path returns a path where entry of given name is written, removing leading path components.
+// The second return value is false if nothing remains after removing them.
+func (t *treeWriter) path(name string) (string, bool, error) {
+	if path.IsAbs(name) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", false, withCode(ErrUnsafeArchive, fmt.Errorf("entry in archive had absolute path: %s", name))
+	}
+	cleaned := path.Clean("/" + name)[1:]
+	if cleaned != path.Clean(name) && path.Clean(name) != "." {
+		return "", false, withCode(ErrUnsafeArchive, fmt.Errorf("entry in archive escaped destination: %s", name))
+	}
+	components := strings.Split(cleaned, "/")
+	if cleaned == "" || len(components) <= t.strip {
+		return "", false, nil
+	}
+	return filepath.Join(t.root, filepath.FromSlash(strings.Join(components[t.strip:], "/"))), true, nil
+}
+
+// checkParents returns an error if any parent directory of given path under root is a symbolic link, so that nothing is written through it.
+func (t *treeWriter) checkParents(p string) error {
+	rel, err := filepath.Rel(t.root, filepath.Dir(p))
+	if err != nil {
+		return err
+	}
+	if rel == "." {
+		return nil
+	}
+	current := t.root
+	for _, c := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, c)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return withCode(ErrUnsafeArchive, fmt.Errorf("entry in archive was written through symbolic link %s", current))
+		}
+	}
+	return nil
+}
+
+// mkdir creates directory at given path.
+func (t *treeWriter) mkdir(p string) error {
+	if err := t.checkParents(p); err != nil {
+		return err
+	}
+	return os.MkdirAll(p, 0755)
+}
+
+// prepare creates parent directories of given path and removes file which already exists at it, so that later entry in archive wins.
+// Directory at given path is kept.
+func (t *treeWriter) prepare(p string) error {
+	if err := t.checkParents(p); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	info, err := os.Lstat(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("directory already existed at %s", p)
+	}
+	return os.Remove(p)
+}
+
+// writeFile writes content read from r into regular file at given path.
+// Permission is limited to owner-writable one so that extracted files are never world-writable or setuid.
+func (t *treeWriter) writeFile(p string, r io.Reader, perm fs.FileMode) error {
+	if err := t.prepare(p); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm&0755|0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		return errors.Join(err, f.Close())
+	}
+	return f.Close()
+}
+
+// symlink creates symbolic link at given path. Target must be relative and stay in root.
+func (t *treeWriter) symlink(p string, target string) error {
+	if filepath.IsAbs(target) || path.IsAbs(target) {
+		return withCode(ErrUnsafeArchive, fmt.Errorf("symbolic link pointed absolute path: %s", target))
+	}
+	if !within(t.root, filepath.Join(filepath.Dir(p), filepath.FromSlash(target))) {
+		return withCode(ErrUnsafeArchive, fmt.Errorf("symbolic link pointed outside destination: %s", target))
+	}
+	if err := t.prepare(p); err != nil {
+		return err
+	}
+	if err := os.Symlink(target, p); err != nil {
+		return err
+	}
+	t.symlinks = append(t.symlinks, p)
+	return nil
+}
+
+// link creates hard link at given path to regular file already extracted. Target is a name of entry in archive.
+func (t *treeWriter) link(p string, target string) error {
+	targetPath, ok, err := t.path(target)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("hard link pointed stripped entry: %s", target)
+	}
+	if err := t.checkParents(targetPath); err != nil {
+		return err
+	}
+	info, err := os.Lstat(targetPath)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("hard link pointed other than regular file: %s", target)
+	}
+	if err := t.prepare(p); err != nil {
+		return err
+	}
+	return os.Link(targetPath, p)
+}
+
+// checkSymlinks returns an error if any symbolic link resolves outside root.
+// Each link target is checked when it is created, but chained links can still escape through parent directory entries of other links.
+func (t *treeWriter) checkSymlinks() error {
+	for _, p := range t.symlinks {
+		resolved, err := filepath.EvalSymlinks(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue // Dangling link points nothing.
+		}
+		if err != nil {
+			return err
+		}
+		if !within(t.root, resolved) {
+			return withCode(ErrUnsafeArchive, fmt.Errorf("symbolic link %s resolved outside destination: %s", p, resolved))
+		}
+	}
+	return nil
+}
+
+// within returns true if given path is root or under it.
+func within(root string, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// findInTree returns a path of regular file which has given name in given directory.
+// Symbolic link which has the name is also returned if it resolves to regular file in directory, as "bin/tool -> ../libexec/tool" in some archives.
+// If multiple files have the name, the one nearest to root is returned.
+func findInTree(root string, name string) (string, error) {
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	found := ""
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Name() != name {
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			resolved, err := filepath.EvalSymlinks(p)
+			if err != nil || !within(resolvedRoot, resolved) {
+				return nil
+			}
+			if info, err := os.Stat(resolved); err != nil || !info.Mode().IsRegular() {
+				return nil
+			}
+		} else if !d.Type().IsRegular() {
+			return nil
+		}
+		if found == "" || strings.Count(p, string(filepath.Separator)) < strings.Count(found, string(filepath.Separator)) {
+			found = p
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if found == "" {
+		return "", withCode(ErrExecBinaryNotFound, fmt.Errorf("%s was not found in asset", name))
+	}
+	return found, nil
+}
+
+// installTree extracts whole release asset into tree root under prefix and links executable binary in it into directory.
+// Archive is extracted into temporary directory first and is moved to tree root only after executable binary in it is found and inspected.
+// If linking or verification fails, new tree is removed and previous executable binary, links and tree are restored.
+// Tree of previous release is removed only after new one is installed.
+func (app *ApplicationService) installTree(ctx context.Context, tag string, asset Asset, assetContent AssetContent, execBinary ExecBinary, opts InstallOptions) (InstallResult, error) {
+	release := Release{
+		tag: tag,
+	}
+	root, err := filepath.Abs(opts.tree.treeRoot(app.repo, release))
+	if err != nil {
+		return InstallResult{}, err
+	}
+	prefix, err := filepath.Abs(opts.tree.prefix)
+	if err != nil {
+		return InstallResult{}, err
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", root, os.Getpid())
+	defer os.RemoveAll(tmp) // nolint:errcheck
+	if err := assetContent.extractTree(ctx, tmp, opts.tree.strip, opts.limits); err != nil {
+		return InstallResult{}, err
+	}
+
+	found, err := findInTree(tmp, execBinary.name)
+	if err != nil {
+		return InstallResult{}, err
+	}
+	execBinaryContent, err := os.ReadFile(found)
+	if err != nil {
+		return InstallResult{}, err
+	}
+	format, err := ExecBinaryContent(execBinaryContent).inspect()
+	if err != nil {
+		return InstallResult{}, err
+	}
+	archMismatch := format.check(defaultPlatform)
+	if archMismatch != nil && !opts.allowArchMismatch {
+		return InstallResult{}, archMismatch
+	}
+	for _, l := range opts.tree.links {
+		if err := checkTreeLink(tmp, l); err != nil {
+			return InstallResult{}, err
+		}
+	}
+
+	result := InstallResult{
+		format:       format,
+		archMismatch: archMismatch,
+		size:         len(execBinaryContent),
+		digest:       digest(execBinaryContent),
+	}
+	previous, _, err := app.installed(execBinary)
+	if err != nil {
+		return InstallResult{}, err
+	}
+	if err := app.checkTreeOwner(root); err != nil {
+		return InstallResult{}, err
+	}
+	if !opts.force && previous.Tree != nil && previous.Tree.Root == root && previous.Digest == result.digest {
+		installed, err := app.execBinary.read(execBinary)
+		if err == nil && digest(installed) == result.digest {
+			result.upToDate = true
+			return result, nil
+		}
+	}
+
+	rel, err := filepath.Rel(tmp, found)
+	if err != nil {
+		return InstallResult{}, err
+	}
+	path, err := filepath.Abs(app.execBinary.path(execBinary))
+	if err != nil {
+		return InstallResult{}, err
+	}
+	record := TreeRecord{
+		Root:            root,
+		Prefix:          prefix,
+		StripComponents: opts.tree.strip,
+		Links:           opts.tree.links,
+	}
+
+	restoreExecBinary, err := app.execBinary.backup(execBinary)
+	if err != nil {
+		return InstallResult{}, err
+	}
+	if target, err := os.Readlink(path); err == nil {
+		// Link into previous tree is restored as link, not as copy of executable binary.
+		restoreExecBinary = func() error { return symlinkAtomic(target, path) }
+	}
+	// Tree which already exists at root, such as one of same release reinstalled with --force, is moved aside until new one is verified.
+	old := fmt.Sprintf("%s.%d.old", root, os.Getpid())
+	if err := os.Rename(root, old); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return InstallResult{}, err
+	}
+	if err := os.Rename(tmp, root); err != nil {
+		return InstallResult{}, errors.Join(err, restoreTree(root, old))
+	}
+	restore := func() error {
+		errs := []error{restoreExecBinary()}
+		for _, p := range record.linkPaths(filepath.Dir(path)) {
+			errs = append(errs, removeLinkInto(p, root))
+		}
+		errs = append(errs, restoreTree(root, old))
+		if previous.Tree != nil {
+			errs = append(errs, relinkTree(*previous.Tree, filepath.Dir(path)))
+		}
+		return errors.Join(errs...)
+	}
+
+	if err := linkTree(path, filepath.Join(root, rel), record); err != nil {
+		return InstallResult{}, errors.Join(err, restore())
+	}
+
+	if opts.verifyRun != "" {
+		if err := verifyExecBinary(ctx, path, opts.verifyRun, release, opts.verifyVersion, opts.verifyTimeout); err != nil {
+			return InstallResult{}, errors.Join(err, restore())
+		}
+		result.verified = true
+	}
+
+	companions, shareDir, err := updateCompanions(ctx, assetContent, execBinary, opts, previous)
+	if err != nil {
+		return InstallResult{}, errors.Join(err, restore())
+	}
+	result.record = InstallRecord{
+		Repo:         app.repo.String(),
+		Tag:          release.tag,
+		AssetURL:     asset.downloadURL.String(),
+		WorkflowRun:  opts.workflowRun,
+		Name:         execBinary.name,
+		Patterns:     opts.patterns,
+		NameTemplate: opts.installName,
+		Path:         path,
+		Digest:       result.digest,
+		InstalledAt:  time.Now().UTC(),
+		Companions:   companions,
+		ShareDir:     shareDir,
+		Tree:         &record,
+	}
+	if err := app.state.save(result.record); err != nil {
+		return InstallResult{}, errors.Join(err, restore())
+	}
+	if err := os.RemoveAll(old); err != nil {
+		return InstallResult{}, err
+	}
+	if previous.Tree != nil {
+		if err := app.removeStaleTree(*previous.Tree, record, filepath.Dir(path)); err != nil {
+			return InstallResult{}, err
+		}
+	}
+	return result, nil
+}
+
+// sameTree returns true if executable binary of given record was installed with or without archive tree as given options tell, and into same tree root if any.
+// Executable binary installed in other way is replaced even if it is same.
+func (app *ApplicationService) sameTree(record InstallRecord, release Release, opts InstallOptions) bool {
+	if opts.tree == nil || record.Tree == nil {
+		return opts.tree == nil && record.Tree == nil
+	}
+	root, err := filepath.Abs(opts.tree.treeRoot(app.repo, release))
+	return err == nil && record.Tree.Root == root
+}
+
+// planTree extracts whole archive into temporary directory in the same way as [ApplicationService.installTree] and returns executable binary in it.
+// Additional executable binaries to link are checked too. Temporary directory is removed before this returns.
+func planTree(ctx context.Context, assetContent AssetContent, execBinary ExecBinary, opts TreeOptions, limits ExtractLimits) (ExecBinaryContent, error) {
+	tmp, err := os.MkdirTemp("", "gh-release-install-tree-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp) // nolint:errcheck
+	root := filepath.Join(tmp, "root")
+	if err := assetContent.extractTree(ctx, root, opts.strip, limits); err != nil {
+		return nil, err
+	}
+	found, err := findInTree(root, execBinary.name)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range opts.links {
+		if err := checkTreeLink(root, l); err != nil {
+			return nil, err
+		}
+	}
+	return os.ReadFile(found)
+}
+
+// checkTreeOwner returns an error if [InstallRecord] shows that given tree root belongs to other repository.
+func (app *ApplicationService) checkTreeOwner(root string) error {
+	records, err := app.state.list()
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if r.Tree != nil && r.Tree.Root == root && r.Repo != app.repo.String() {
+			return fmt.Errorf("%s is tree of %s installed from %s; refusing to replace it", root, r.installName(), r.Repo)
+		}
+	}
+	return nil
+}
+
+// restoreTree removes new tree at given root and moves tree which was moved aside to given path back to root if it exists.
+func restoreTree(root string, old string) error {
+	if err := os.RemoveAll(root); err != nil {
+		return err
+	}
+	if err := os.Rename(old, root); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// relinkTree creates symbolic links to additional executable binaries in tree of given record into directory again if they are missing.
+func relinkTree(record TreeRecord, dir string) error {
+	errs := []error{}
+	for i, p := range record.linkPaths(dir) {
+		if _, err := os.Lstat(p); errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, symlinkAtomic(filepath.Join(record.Root, filepath.FromSlash(record.Links[i])), p))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkTreeLink returns an error if given path of additional executable binary is not a regular file in tree.
+func checkTreeLink(root string, l string) error {
+	if filepath.IsAbs(l) || !filepath.IsLocal(filepath.FromSlash(l)) {
+		return fmt.Errorf("path to link must be relative path in tree: %s", l)
+	}
+	info, err := os.Lstat(filepath.Join(root, filepath.FromSlash(l)))
+	if err != nil {
+		return fmt.Errorf("%s was not found in tree: %w", l, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s in tree was not regular file", l)
+	}
+	return nil
+}
+
+// linkTree creates symbolic links to executable binary and additional ones in tree into directory of given path under its lock.
+// Additional links never replace files other than symbolic links.
+func linkTree(path string, target string, record TreeRecord) error {
+	dir := filepath.Dir(path)
+	unlock, err := lockDir(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock() // nolint:errcheck
+	for i, p := range record.linkPaths(dir) {
+		if info, err := os.Lstat(p); err == nil && info.Mode()&fs.ModeSymlink == 0 {
+			return fmt.Errorf("%s already exists and is not a symbolic link", p)
+		}
+		if err := symlinkAtomic(filepath.Join(record.Root, filepath.FromSlash(record.Links[i])), p); err != nil {
+			return err
+		}
+	}
+	return symlinkAtomic(target, path)
+}
+
+// removeStaleTree removes links and tree of previous installation which are no longer used by current one.
+// Tree is kept if other executable binary installed by this tool still links into it.
+func (app *ApplicationService) removeStaleTree(previous TreeRecord, current TreeRecord, dir string) error {
+	errs := []error{}
+	for _, p := range previous.linkPaths(dir) {
+		if !slices.Contains(current.linkPaths(dir), p) {
+			errs = append(errs, removeLinkInto(p, previous.Root))
+		}
+	}
+	if previous.Root == current.Root {
+		return errors.Join(errs...)
+	}
+	records, err := app.state.list()
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	if !slices.ContainsFunc(records, func(r InstallRecord) bool { return r.Tree != nil && r.Tree.Root == previous.Root }) {
+		errs = append(errs, os.RemoveAll(previous.Root))
+	}
+	return errors.Join(errs...)
+}
+
+// removeLinkInto removes symbolic link at given path only if it points into given tree root.
+func removeLinkInto(p string, root string) error {
+	target, err := os.Readlink(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return nil // Not a symbolic link, which is not created by this tool.
+	}
+	if !within(root, target) {
+		return nil
+	}
+	return os.Remove(p)
+}
@@ -0,0 +1,409 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// tarEntry is an entry of tarball built for tests.
+type tarEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+// newTarball returns gzip compressed tarball which has given entries.
+func newTarball(t *testing.T, entries []tarEntry) AssetContent {
+	t.Helper()
+	var b bytes.Buffer
+	gw := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0755, Size: int64(len(e.content)), Linkname: e.linkname}
+		if e.typeflag != tar.TypeReg {
+			header.Size = 0
+		}
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write([]byte(e.content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+	return b.Bytes()
+}
+
+func TestExtractTree(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		strip   int
+		files   []string
+		wantErr bool
+	}{
+		{
+			name: "Tree",
+			entries: []tarEntry{
+				{name: "tool-1.0.0/", typeflag: tar.TypeDir},
+				{name: "tool-1.0.0/bin/tool", typeflag: tar.TypeReg, content: "tool"},
+				{name: "tool-1.0.0/lib/data.txt", typeflag: tar.TypeReg, content: "data"},
+				{name: "tool-1.0.0/bin/alias", typeflag: tar.TypeSymlink, linkname: "tool"},
+				{name: "tool-1.0.0/bin/hardlink", typeflag: tar.TypeLink, linkname: "tool-1.0.0/bin/tool"},
+			},
+			strip: 1,
+			files: []string{"bin/tool", "lib/data.txt", "bin/alias", "bin/hardlink"},
+		},
+		{
+			name:    "AbsolutePath",
+			entries: []tarEntry{{name: "/etc/passwd", typeflag: tar.TypeReg, content: "root"}},
+			wantErr: true,
+		},
+		{
+			name:    "PathTraversal",
+			entries: []tarEntry{{name: "tool/../../evil", typeflag: tar.TypeReg, content: "evil"}},
+			wantErr: true,
+		},
+		{
+			name:    "PathTraversalStripped",
+			entries: []tarEntry{{name: "../evil", typeflag: tar.TypeReg, content: "evil"}},
+			strip:   1,
+			wantErr: true,
+		},
+		{
+			name:    "AbsoluteSymbolicLink",
+			entries: []tarEntry{{name: "tool/passwd", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"}},
+			wantErr: true,
+		},
+		{
+			name:    "SymbolicLinkEscaping",
+			entries: []tarEntry{{name: "tool/up", typeflag: tar.TypeSymlink, linkname: "../.."}},
+			wantErr: true,
+		},
+		{
+			name: "WriteThroughSymbolicLink",
+			entries: []tarEntry{
+				{name: "tool/dir", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "tool/dir/file", typeflag: tar.TypeReg, content: "file"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "ChainedSymbolicLinksEscaping",
+			entries: []tarEntry{
+				{name: "a/b/c", typeflag: tar.TypeSymlink, linkname: "../.."},
+				{name: "a/d", typeflag: tar.TypeSymlink, linkname: "b/c/.."},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "HardLinkEscaping",
+			entries: []tarEntry{{name: "tool/passwd", typeflag: tar.TypeLink, linkname: "../../etc/passwd"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := filepath.Join(t.TempDir(), "root")
+			err := newTarball(t, tt.entries).extractTree(context.Background(), root, tt.strip, ExtractLimits{})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, f := range tt.files {
+				require.FileExists(t, filepath.Join(root, f))
+			}
+		})
+	}
+}
+
+func TestApplicationServiceInstallTree(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	prefix := t.TempDir()
+	b, err := os.ReadFile(os.Args[0])
+	require.NoError(err)
+	tarball := func(version string) AssetContent {
+		return newTarball(t, []tarEntry{
+			{name: "tool-" + version + "/bin/tool", typeflag: tar.TypeReg, content: string(b)},
+			{name: "tool-" + version + "/bin/helper", typeflag: tar.TypeReg, content: "#!/bin/sh"},
+			{name: "tool-" + version + "/lib/data.txt", typeflag: tar.TypeReg, content: version},
+		})
+	}
+	asset := &fakeAssetRepository{
+		contents: map[string]AssetContent{
+			"https://github.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64.tar.gz": tarball("1.0.0"),
+		},
+	}
+	repo := Repository{host: "github.com", owner: "owner", name: "tool"}
+	state := newStateRepository(filepath.Join(dir, ".state.json"))
+	app := newApplicationService(repo, asset, newFSExecBinaryRepository(dir), state)
+	ctx := context.Background()
+	opts := InstallOptions{tree: &TreeOptions{prefix: prefix, strip: 1, links: []string{"bin/helper"}}}
+
+	a, execBinary, err := app.find(ctx, "v1.0.0", defaultPatterns, "")
+	require.NoError(err)
+	result, err := app.install(ctx, "v1.0.0", a, execBinary, opts)
+	require.NoError(err)
+	require.Equal(filepath.Join(prefix, "opt", "owner", "tool", "v1.0.0"), result.record.Tree.Root)
+	target, err := os.Readlink(filepath.Join(dir, "tool"))
+	require.NoError(err)
+	require.Equal(filepath.Join(prefix, "opt", "owner", "tool", "v1.0.0", "bin", "tool"), target)
+	target, err = os.Readlink(filepath.Join(dir, "helper"))
+	require.NoError(err)
+	require.Equal(filepath.Join(prefix, "opt", "owner", "tool", "v1.0.0", "bin", "helper"), target)
+	require.FileExists(filepath.Join(prefix, "opt", "owner", "tool", "v1.0.0", "lib", "data.txt"))
+
+	upToDate, err := app.upToDate(ctx, "v1.0.0", execBinary, opts)
+	require.NoError(err)
+	require.True(upToDate)
+
+	asset.contents = map[string]AssetContent{
+		"https://github.com/owner/tool/releases/download/v1.1.0/tool_linux_amd64.tar.gz": tarball("1.1.0"),
+	}
+	a, execBinary, err = app.find(ctx, "v1.1.0", defaultPatterns, "")
+	require.NoError(err)
+	opts.tree.links = nil
+	result, err = app.install(ctx, "v1.1.0", a, execBinary, opts)
+	require.NoError(err)
+	require.NoDirExists(filepath.Join(prefix, "opt", "owner", "tool", "v1.0.0"), "tree of previous release should be removed")
+	require.NoFileExists(filepath.Join(dir, "helper"), "link which is no longer requested should be removed")
+	require.FileExists(filepath.Join(prefix, "opt", "owner", "tool", "v1.1.0", "lib", "data.txt"))
+
+	require.NoError(removeTree(state, result.record))
+	require.NoDirExists(filepath.Join(prefix, "opt", "owner", "tool", "v1.1.0"))
+}
+
+func TestCheckTreeLink(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "bin"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "bin", "tool"), []byte("tool"), 0755))
+
+	for l, ok := range map[string]bool{
+		"bin/tool":         true,
+		"bin":              false,
+		"bin/missing":      false,
+		"/bin/tool":        false,
+		"../bin/tool":      false,
+		"bin/../../etc/sh": false,
+	} {
+		t.Run(l, func(t *testing.T) {
+			err := checkTreeLink(root, l)
+			if ok {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestApplicationServiceInstallTreeRestore(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	prefix := t.TempDir()
+	b, err := os.ReadFile(os.Args[0])
+	require.NoError(err)
+	asset := &fakeAssetRepository{}
+	useTarball := func(tag string, data string) {
+		asset.contents = map[string]AssetContent{
+			"https://github.com/owner/tool/releases/download/" + tag + "/tool_linux_amd64.tar.gz": newTarball(t, []tarEntry{
+				{name: "tool/bin/tool", typeflag: tar.TypeReg, content: string(b)},
+				{name: "tool/bin/helper", typeflag: tar.TypeReg, content: "#!/bin/sh"},
+				{name: "tool/lib/data.txt", typeflag: tar.TypeReg, content: data},
+			}),
+		}
+	}
+	repo := Repository{host: "github.com", owner: "owner", name: "tool"}
+	state := newStateRepository(filepath.Join(dir, ".state.json"))
+	app := newApplicationService(repo, asset, newFSExecBinaryRepository(dir), state)
+	ctx := context.Background()
+	install := func(tag string, opts InstallOptions) error {
+		a, execBinary, err := app.find(ctx, tag, defaultPatterns, "")
+		require.NoError(err)
+		_, err = app.install(ctx, tag, a, execBinary, opts)
+		return err
+	}
+	root := filepath.Join(prefix, "opt", "owner", "tool", "v1.0.0")
+	requireInstalled := func() {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(root, "lib", "data.txt"))
+		require.NoError(err)
+		require.Equal("old", string(data))
+		for _, name := range []string{"tool", "helper"} {
+			target, err := os.Readlink(filepath.Join(dir, name))
+			require.NoError(err)
+			require.Equal(filepath.Join(root, "bin", name), target)
+		}
+		entries, err := os.ReadDir(filepath.Dir(root))
+		require.NoError(err)
+		require.Len(entries, 1, "new, temporary and moved aside trees should be removed")
+	}
+
+	useTarball("v1.0.0", "old")
+	require.NoError(install("v1.0.0", InstallOptions{tree: &TreeOptions{prefix: prefix, strip: 1, links: []string{"bin/helper"}}}))
+	requireInstalled()
+
+	failing := InstallOptions{verifyRun: "tool -no-such-flag", verifyTimeout: 10 * time.Second, tree: &TreeOptions{prefix: prefix, strip: 1, links: []string{"bin/helper"}}}
+
+	useTarball("v1.0.0", "new")
+	failing.force = true
+	err = install("v1.0.0", failing)
+	require.Equal(ErrVerificationFailed, errorCode(err))
+	requireInstalled()
+
+	useTarball("v1.1.0", "new")
+	failing.force = false
+	err = install("v1.1.0", failing)
+	require.Equal(ErrVerificationFailed, errorCode(err))
+	requireInstalled()
+}
+
+func TestApplicationServiceInstallTreeOwner(t *testing.T) {
+	require := require.New(t)
+
+	prefix := t.TempDir()
+	opts := TreeOptions{prefix: prefix}
+	release := Release{tag: "v1.0.0"}
+	require.NotEqual(
+		opts.treeRoot(Repository{host: "github.com", owner: "foo", name: "cli"}, release),
+		opts.treeRoot(Repository{host: "github.com", owner: "bar", name: "cli"}, release),
+	)
+
+	dir := t.TempDir()
+	app := newFakeApplicationService(t, dir)
+	root := opts.treeRoot(app.repo, release)
+	require.NoError(app.state.save(InstallRecord{Repo: "gitlab.com/owner/tool", Path: filepath.Join(t.TempDir(), "tool"), Tree: &TreeRecord{Root: root}}))
+	require.NoError(os.MkdirAll(root, 0755))
+
+	a, execBinary, err := app.find(context.Background(), "v1.0.0", defaultPatterns, "")
+	require.NoError(err)
+	_, err = app.install(context.Background(), "v1.0.0", a, execBinary, InstallOptions{tree: &opts})
+	require.Error(err)
+	require.DirExists(root)
+}
+
+func TestApplicationServicePlanTree(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	prefix := t.TempDir()
+	shareDir := t.TempDir()
+	b, err := os.ReadFile(os.Args[0])
+	require.NoError(err)
+	asset := &fakeAssetRepository{
+		contents: map[string]AssetContent{
+			"https://github.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64.tar.gz": newTarball(t, []tarEntry{
+				{name: "tool/bin/tool", typeflag: tar.TypeReg, content: string(b)},
+				{name: "tool/share/man/man1/tool.1", typeflag: tar.TypeReg, content: ".TH TOOL 1"},
+			}),
+		},
+	}
+	repo := Repository{host: "github.com", owner: "owner", name: "tool"}
+	app := newApplicationService(repo, asset, newFSExecBinaryRepository(dir), newStateRepository(filepath.Join(dir, ".state.json")))
+	ctx := context.Background()
+	opts := InstallOptions{shareDir: shareDir, tree: &TreeOptions{prefix: prefix, strip: 1}}
+	root := filepath.Join(prefix, "opt", "owner", "tool", "v1.0.0")
+
+	a, execBinary, err := app.find(ctx, "v1.0.0", defaultPatterns, "")
+	require.NoError(err)
+
+	plan, err := app.plan(ctx, "v1.0.0", a, execBinary, opts, false)
+	require.NoError(err)
+	require.Equal(root, plan.treeRoot)
+	require.False(plan.extracted)
+
+	plan, err = app.plan(ctx, "v1.0.0", a, execBinary, opts, true)
+	require.NoError(err)
+	require.True(plan.extracted)
+	require.Equal(digest(b), plan.digest)
+	require.Equal([]string{filepath.Join(shareDir, "man", "man1", "tool.1")}, plan.companions)
+	require.NoDirExists(root, "dry run should write nothing")
+	require.NoFileExists(filepath.Join(shareDir, "man", "man1", "tool.1"), "dry run should write nothing")
+
+	opts.tree.links = []string{"bin/missing"}
+	_, err = app.plan(ctx, "v1.0.0", a, execBinary, opts, true)
+	require.Error(err, "missing link should be reported without installing")
+
+	opts.tree.links = nil
+	_, err = app.install(ctx, "v1.0.0", a, execBinary, opts)
+	require.NoError(err)
+	plan, err = app.plan(ctx, "v1.0.0", a, execBinary, opts, false)
+	require.NoError(err)
+	require.True(plan.upToDate)
+	plan, err = app.plan(ctx, "v1.0.0", a, execBinary, InstallOptions{}, false)
+	require.NoError(err)
+	require.False(plan.upToDate, "executable binary linked into tree should be replaced when tree is not requested")
+}
+
+func TestFindInTree(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, root string)
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "RegularFile",
+			setup: func(t *testing.T, root string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(root, "sub", "bin"), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(root, "sub", "bin", "tool"), []byte("deep"), 0755))
+				require.NoError(t, os.MkdirAll(filepath.Join(root, "bin"), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(root, "bin", "tool"), []byte("tool"), 0755))
+			},
+			want: filepath.Join("bin", "tool"),
+		},
+		{
+			name: "SymlinkToFileInRoot",
+			setup: func(t *testing.T, root string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(root, "libexec"), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(root, "libexec", "tool-real"), []byte("tool"), 0755))
+				require.NoError(t, os.MkdirAll(filepath.Join(root, "bin"), 0755))
+				require.NoError(t, os.Symlink(filepath.Join("..", "libexec", "tool-real"), filepath.Join(root, "bin", "tool")))
+			},
+			want: filepath.Join("bin", "tool"),
+		},
+		{
+			name: "SymlinkOutsideRoot",
+			setup: func(t *testing.T, root string) {
+				outside := filepath.Join(t.TempDir(), "tool")
+				require.NoError(t, os.WriteFile(outside, []byte("tool"), 0755))
+				require.NoError(t, os.Symlink(outside, filepath.Join(root, "tool")))
+			},
+			wantErr: true,
+		},
+		{
+			name: "SymlinkToDirectory",
+			setup: func(t *testing.T, root string) {
+				require.NoError(t, os.MkdirAll(filepath.Join(root, "lib"), 0755))
+				require.NoError(t, os.Symlink("lib", filepath.Join(root, "tool")))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			tt.setup(t, root)
+			p, err := findInTree(root, "tool")
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(root, tt.want), p)
+		})
+	}
+}
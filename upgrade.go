@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Upgrade is a pair of [InstallRecord] of installed executable binary and [Release] which it can be upgraded to.
+type Upgrade struct {
+	record  InstallRecord
+	repo    Repository
+	release Release
+}
+
+// findUpgrades returns [Upgrade] objects of executable binaries which have given names and newer releases.
+// If names are empty, this checks all executable binaries installed by this tool.
+// Executable binaries installed from artifacts of workflow runs are skipped because their pseudo tags can't be compared with releases.
+// Upgrade is offered only if both current and latest tags are semantic versions, because other tags which merely differ may be downgrade.
+// Latest release of each executable binary is looked up in [ReleaseRepository] returned by given function.
+// Executable binaries whose latest releases can't be found don't stop checking others; this returns errors about them as failed.
+func findUpgrades(ctx context.Context, state StateRepository, names []string, releaseRepositoryOf func(Repository) (ReleaseRepository, error)) ([]Upgrade, []error, error) {
+	records, err := state.list()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(records, func(r InstallRecord) bool { return r.installName() == name }) {
+			return nil, nil, fmt.Errorf("%s was not installed by gh-release-install", name)
+		}
+	}
+
+	upgrades := []Upgrade{}
+	failed := []error{}
+	for _, record := range records {
+		if len(names) > 0 && !slices.Contains(names, record.installName()) {
+			continue
+		}
+		if record.WorkflowRun != 0 {
+			continue
+		}
+		repo, err := parseRepository(record.Repo)
+		if err != nil {
+			failed = append(failed, fmt.Errorf("failed to check %s: %w", record.installName(), err))
+			continue
+		}
+		releaseRepository, err := releaseRepositoryOf(repo)
+		if err != nil {
+			failed = append(failed, fmt.Errorf("failed to check %s: %w", record.installName(), err))
+			continue
+		}
+		latest, err := releaseRepository.latest(ctx)
+		if err != nil {
+			failed = append(failed, fmt.Errorf("failed to find the latest release of %s: %w", record.Repo, err))
+			continue
+		}
+		current := Release{tag: record.Tag}
+		if latest.semVer() != "" && current.semVer() != "" && latest.newerThan(current) {
+			upgrades = append(upgrades, Upgrade{
+				record:  record,
+				repo:    repo,
+				release: latest,
+			})
+		}
+	}
+	return upgrades, failed, nil
+}
+
+// application returns [ApplicationService] object which installs executable binary into same directory or store as it was installed.
+func (u Upgrade) application(state StateRepository, config Config) (*ApplicationService, error) {
+	assetRepository, err := newAssetRepository(u.record.Repo, config, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	execBinaryRepository, err := newExecBinaryRepository(u.record.Repo, filepath.Dir(u.record.Path), storeOf(u.record.Path, u.repo))
+	if err != nil {
+		return nil, err
+	}
+	return newApplicationService(u.repo, assetRepository, execBinaryRepository, state), nil
+}
+
+// upgrade installs executable binary from newer release by given [ApplicationService] in same way as it was installed.
+func (u Upgrade) upgrade(ctx context.Context, app *ApplicationService, opts InstallOptions) error {
+	patterns := u.record.Patterns
+	if len(patterns) == 0 {
+		patterns = defaultPatterns
+	}
+
+	asset, execBinary, err := app.find(ctx, u.release.tag, patterns, u.record.NameTemplate)
+	if err != nil {
+		return err
+	}
+
+	opts.patterns = u.record.Patterns
+	opts.installName = u.record.NameTemplate
+	opts.shareDir = u.record.ShareDir
+	if u.record.Tree != nil {
+		opts.tree = u.record.Tree.options()
+	}
+	_, err = app.install(ctx, u.release.tag, asset, execBinary, opts)
+	return err
+}
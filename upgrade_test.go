@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"debug/elf"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindUpgrades(t *testing.T) {
+	state := newStateRepository(filepath.Join(t.TempDir(), "state.json"))
+	require.NoError(t, state.save(InstallRecord{Repo: "github.com/owner/tool", Tag: "v1.0.0", Path: "/usr/local/bin/tool"}))
+	require.NoError(t, state.save(InstallRecord{Repo: "github.com/owner/other", Tag: "v2.0.0", Path: "/usr/local/bin/other"}))
+	require.NoError(t, state.save(InstallRecord{Repo: "github.com/owner/tool", Tag: "run-123", WorkflowRun: 123, Path: "/opt/bin/tool"}))
+	require.NoError(t, state.save(InstallRecord{Repo: "github.com/owner/nightly", Tag: "nightly-2", Path: "/usr/local/bin/nightly"}))
+	require.NoError(t, state.save(InstallRecord{Repo: "github.com/owner/deleted", Tag: "v1.0.0", Path: "/usr/local/bin/deleted"}))
+	releaseRepositories := map[string]*fakeReleaseRepository{
+		"github.com/owner/tool":    {tags: []string{"v1.1.0", "v1.0.0"}},
+		"github.com/owner/other":   {tags: []string{"v2.0.0", "v1.0.0"}},
+		"github.com/owner/nightly": {tags: []string{"nightly-1", "nightly-2"}},
+	}
+	releaseRepositoryOf := func(repo Repository) (ReleaseRepository, error) {
+		r, ok := releaseRepositories[repo.String()]
+		if !ok {
+			return nil, fmt.Errorf("%s was not found", repo)
+		}
+		return r, nil
+	}
+
+	tests := []struct {
+		name    string
+		names   []string
+		want    map[string]string
+		failed  int
+		wantErr bool
+	}{
+		{
+			name:   "All",
+			names:  nil,
+			want:   map[string]string{"/usr/local/bin/tool": "v1.1.0"},
+			failed: 1,
+		},
+		{
+			name:  "OutdatedButWorkflowRunSkipped",
+			names: []string{"tool"},
+			want:  map[string]string{"/usr/local/bin/tool": "v1.1.0"},
+		},
+		{
+			name:  "UpToDate",
+			names: []string{"other"},
+			want:  map[string]string{},
+		},
+		{
+			name:  "TagsNotSemanticVersion",
+			names: []string{"nightly"},
+			want:  map[string]string{},
+		},
+		{
+			name:   "LatestReleaseNotFound",
+			names:  []string{"deleted"},
+			want:   map[string]string{},
+			failed: 1,
+		},
+		{
+			name:    "NotInstalled",
+			names:   []string{"missing"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upgrades, failed, err := findUpgrades(context.Background(), state, tt.names, releaseRepositoryOf)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, failed, tt.failed)
+			got := map[string]string{}
+			for _, u := range upgrades {
+				got[u.record.Path] = u.release.tag
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUpgradeUpgrade(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	v1 := elfFixture(t, elf.ELFOSABI_NONE, elf.EM_X86_64)
+	v2 := append(elfFixture(t, elf.ELFOSABI_NONE, elf.EM_X86_64), 0)
+	asset := &fakeAssetRepository{
+		contents: map[string]AssetContent{
+			"https://github.com/owner/tool/releases/download/v1.0.0/tool_linux_amd64": v1,
+		},
+	}
+	repo := Repository{host: "github.com", owner: "owner", name: "tool"}
+	state := newStateRepository(filepath.Join(dir, ".state.json"))
+	app := newApplicationService(repo, asset, newFSExecBinaryRepository(dir), state)
+	ctx := context.Background()
+
+	a, execBinary, err := app.find(ctx, "v1.0.0", defaultPatterns, "")
+	require.NoError(err)
+	result, err := app.install(ctx, "v1.0.0", a, execBinary, InstallOptions{})
+	require.NoError(err)
+
+	asset.contents = map[string]AssetContent{
+		"https://github.com/owner/tool/releases/download/v1.1.0/tool_linux_amd64": v2,
+	}
+	u := Upgrade{record: result.record, repo: repo, release: Release{tag: "v1.1.0"}}
+	require.NoError(u.upgrade(ctx, app, InstallOptions{}))
+
+	content, err := os.ReadFile(filepath.Join(dir, "tool"))
+	require.NoError(err)
+	require.Equal(v2, content)
+	records, err := findInstallRecords(state, "tool", dir)
+	require.NoError(err)
+	require.Len(records, 1)
+	require.Equal("v1.1.0", records[0].Tag)
+	require.Equal(digest(v2), records[0].Digest)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// runExecBinary runs installed executable binary by given command and returns its combined output.
+// First word of command names executable binary and is always replaced with path of installed one,
+// so that command found in PATH is never run instead even if installed one has other name.
+// Command is run with minimal environment variables and is killed if it doesn't exit within timeout.
+func runExecBinary(ctx context.Context, path string, command string, timeout time.Duration) (string, error) {
+	args, err := shellquote.Split(command)
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 0 {
+		return "", errors.New("command to run executable binary was empty")
+	}
+	args[0] = path
+
+	home, err := os.MkdirTemp("", "gh-release-install-run-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(home) // nolint:errcheck
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = home
+	cmd.Env = []string{
+		"PATH=" + strings.Join([]string{filepath.Dir(path), "/usr/local/bin", "/usr/bin", "/bin"}, string(os.PathListSeparator)),
+		"HOME=" + home,
+		"LANG=C",
+	}
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return out.String(), fmt.Errorf("command %q timed out after %s", command, timeout)
+		}
+		return out.String(), fmt.Errorf("command %q failed: %w: %s", command, err, strings.TrimSpace(out.String()))
+	}
+	return out.String(), nil
+}
+
+// containsVersion returns true if given output of executable binary contains release tag or semantic version.
+// Version must be delimited, so that "1.2.3" doesn't match "1.2.30", "v11.2.3" nor "1.2.3-rc.1".
+func containsVersion(out string, release Release) bool {
+	return versionPattern(release.tag).MatchString(out) || (release.semVer() != "" && versionPattern(release.semVer()).MatchString(out))
+}
+
+// versionPattern returns regular expression which matches given version delimited in output of executable binary.
+// Version may be prefixed with "v" and followed by build metadata such as "+abc" or period which ends sentence.
+func versionPattern(version string) *regexp.Regexp {
+	return regexp.MustCompile(`(?:^|[^0-9A-Za-z.])v?` + regexp.QuoteMeta(version) + `(?:$|[^0-9A-Za-z.\-]|[.\-](?:$|[^0-9A-Za-z]))`)
+}
+
+// verifyExecBinary runs freshly installed executable binary by given command to verify it works.
+// If checkVersion is true, this also requires output of command to contain release tag or semantic version.
+func verifyExecBinary(ctx context.Context, path string, command string, release Release, checkVersion bool, timeout time.Duration) error {
+	out, err := runExecBinary(ctx, path, command, timeout)
+	if err != nil {
+		return withCode(ErrVerificationFailed, fmt.Errorf("verification failed: %w", err))
+	}
+	if checkVersion && !containsVersion(out, release) {
+		return withCode(ErrVerificationFailed, fmt.Errorf("output of verification command %q didn't contain %s: %s", command, release.tag, strings.TrimSpace(out)))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyExecBinary(t *testing.T) {
+	tests := []struct {
+		name         string
+		script       string
+		command      string
+		checkVersion bool
+		ok           bool
+	}{
+		{
+			name:         "VersionMatched",
+			script:       "#!/bin/sh\necho tool version 1.2.3\n",
+			command:      "tool --version",
+			checkVersion: true,
+			ok:           true,
+		},
+		{
+			name:         "VersionUnmatched",
+			script:       "#!/bin/sh\necho tool version 1.2.2\n",
+			command:      "tool --version",
+			checkVersion: true,
+			ok:           false,
+		},
+		{
+			name:         "VersionPrefixUnmatched",
+			script:       "#!/bin/sh\necho tool version 1.2.30\n",
+			command:      "tool --version",
+			checkVersion: true,
+			ok:           false,
+		},
+		{
+			name:         "OtherNameRunsInstalledPath",
+			script:       "#!/bin/sh\necho tool version 1.2.3\n",
+			command:      "true --version",
+			checkVersion: true,
+			ok:           true,
+		},
+		{
+			name:         "ExitWithError",
+			script:       "#!/bin/sh\nexit 1\n",
+			command:      "tool --version",
+			checkVersion: false,
+			ok:           false,
+		},
+		{
+			name:         "TimedOut",
+			script:       "#!/bin/sh\nsleep 10\n",
+			command:      "tool --version",
+			checkVersion: false,
+			ok:           false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			path := filepath.Join(t.TempDir(), "tool")
+			require.NoError(os.WriteFile(path, []byte(tt.script), 0755))
+
+			err := verifyExecBinary(context.Background(), path, tt.command, Release{tag: "v1.2.3"}, tt.checkVersion, time.Second)
+			if tt.ok {
+				require.NoError(err)
+			} else {
+				require.Error(err)
+			}
+		})
+	}
+}
+
+func TestContainsVersion(t *testing.T) {
+	tests := []struct {
+		out  string
+		tag  string
+		want bool
+	}{
+		{out: "tool version 1.2.3", tag: "v1.2.3", want: true},
+		{out: "tool v1.2.3 (abcdef)", tag: "v1.2.3", want: true},
+		{out: "tool 1.2.3+build.1", tag: "v1.2.3", want: true},
+		{out: "Version: 1.2.3.", tag: "1.2.3", want: true},
+		{out: "tool version v1.2.3", tag: "1.2.3", want: true},
+		{out: "go version go1.22.3 linux/amd64", tag: "go1.22.3", want: true},
+		{out: "tool version 1.2.30", tag: "v1.2.3", want: false},
+		{out: "tool version v11.2.3", tag: "v1.2.3", want: false},
+		{out: "tool version 1.2.3.4", tag: "v1.2.3", want: false},
+		{out: "tool version 1.2.3-rc.1", tag: "v1.2.3", want: false},
+		{out: "tool version 21.2.3", tag: "v1.2.3", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.out, func(t *testing.T) {
+			require.Equal(t, tt.want, containsVersion(tt.out, Release{tag: tt.tag}))
+		})
+	}
+}
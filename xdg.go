@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// xdgDataHome returns a base directory relative to which user-specific data files should be written.
+// This follows XDG Base Directory Specification and falls back to "$HOME/.local/share".
+func xdgDataHome() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "share")
+}
+
+// xdgStateHome returns a base directory relative to which user-specific state files should be written.
+// This follows XDG Base Directory Specification and falls back to "$HOME/.local/state".
+func xdgStateHome() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state")
+}
+
+// xdgCacheHome returns a base directory relative to which user-specific non-essential data files should be written.
+// This follows XDG Base Directory Specification and falls back to "$HOME/.cache".
+func xdgCacheHome() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".cache")
+}